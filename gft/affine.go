@@ -0,0 +1,177 @@
+package gft
+
+import (
+	"image"
+	"image/draw"
+
+	"github.com/infastin/gul/gm32"
+	"github.com/infastin/gul/tools"
+)
+
+type affineFilter struct {
+	m                gm32.Mat3
+	interpolation    Interpolation
+	oldInterpolation Interpolation
+	mergeCount       uint
+}
+
+// Returns the bounds of the union of the source image and the transformed image in the transformed space.
+// The source image is included, so that the translation moves the transformed image.
+func (f *affineFilter) transformedBounds(width, height float32) (minX, minY, maxX, maxY float32) {
+	corners := [4]gm32.Vec3{
+		{0, 0, 1},
+		{width, 0, 1},
+		{0, height, 1},
+		{width, height, 1},
+	}
+
+	minX, minY, maxX, maxY = 0, 0, width, height
+
+	for _, c := range corners {
+		p := f.m.MulMat3x1(c)
+
+		minX = gm32.Min(minX, p[0])
+		minY = gm32.Min(minY, p[1])
+		maxX = gm32.Max(maxX, p[0])
+		maxY = gm32.Max(maxY, p[1])
+	}
+
+	return minX, minY, maxX, maxY
+}
+
+func (f *affineFilter) Bounds(src image.Rectangle) image.Rectangle {
+	srcb := src.Bounds()
+	minX, minY, maxX, maxY := f.transformedBounds(float32(srcb.Dx()), float32(srcb.Dy()))
+
+	dstWidth := int(gm32.Round(maxX - minX))
+	dstHeight := int(gm32.Round(maxY - minY))
+
+	return image.Rect(0, 0, dstWidth, dstHeight)
+}
+
+func (f *affineFilter) Apply(dst draw.Image, src image.Image, parallel bool) {
+	srcb := src.Bounds()
+	dstb := dst.Bounds()
+
+	inv, ok := f.m.Inverse()
+	if !ok {
+		return
+	}
+
+	srcWidth := float32(srcb.Dx())
+	srcHeight := float32(srcb.Dy())
+	minX, minY, _, _ := f.transformedBounds(srcWidth, srcHeight)
+
+	// Points outside of the image are transparent,
+	// so edge pixels are only used for interpolation near the edges.
+	pixGetter := newEdgePixelGetter(src, EdgeClamp)
+	pixSetter := newPixelSetter(dst)
+
+	procs := 1
+	if parallel {
+		procs = 0
+	}
+
+	tools.Parallelize(procs, dstb.Min.Y, dstb.Max.Y, 1, func(start, end int) {
+		for yi := start; yi < end; yi++ {
+			for xi := dstb.Min.X; xi < dstb.Max.X; xi++ {
+				// Center of the pixel in the transformed space.
+				x := float32(xi-dstb.Min.X) + 0.5 + minX
+				y := float32(yi-dstb.Min.Y) + 0.5 + minY
+
+				p := inv.MulMat3x1(gm32.Vec3{x, y, 1})
+				if p[0] < 0 || p[1] < 0 || p[0] > srcWidth || p[1] > srcHeight {
+					pixSetter.setPixel(xi, yi, Pixel{0, 0, 0, 0})
+					continue
+				}
+
+				x2 := p[0] - 0.5 + float32(srcb.Min.X)
+				y2 := p[1] - 0.5 + float32(srcb.Min.Y)
+
+				rgba := interpolate(pixGetter, x2, y2, f.interpolation)
+				pixSetter.setPixel(xi, yi, rgba)
+			}
+		}
+	})
+}
+
+func (f *affineFilter) CanMerge(filter Filter) bool {
+	if _, ok := filter.(*affineFilter); ok {
+		return true
+	}
+
+	return false
+}
+
+func (f *affineFilter) Merge(filter Filter) {
+	filt := filter.(*affineFilter)
+
+	f.m = filt.m.MulMat3(f.m)
+	filt.oldInterpolation = f.interpolation
+	f.interpolation = filt.interpolation
+
+	f.mergeCount++
+}
+
+func (f *affineFilter) CanUndo(filter Filter) bool {
+	if _, ok := filter.(*affineFilter); ok {
+		return true
+	}
+
+	return false
+}
+
+func (f *affineFilter) Undo(filter Filter) bool {
+	filt := filter.(*affineFilter)
+
+	inv, ok := filt.m.Inverse()
+	if !ok {
+		return false
+	}
+
+	f.m = inv.MulMat3(f.m)
+	f.interpolation = filt.oldInterpolation
+
+	f.mergeCount--
+	return f.mergeCount == 0
+}
+
+func (f *affineFilter) Skip() bool {
+	return f.m == gm32.Mat3{1, 0, 0, 0, 1, 0, 0, 0, 1}
+}
+
+func (f *affineFilter) Copy() Filter {
+	return &affineFilter{
+		m:                f.m,
+		interpolation:    f.interpolation,
+		oldInterpolation: f.oldInterpolation,
+		mergeCount:       f.mergeCount,
+	}
+}
+
+// Applies an affine transformation (e.g. shear, scale, rotation, translation) to an image
+// using given interpolation method.
+// The m matrix maps the pixel coordinates (x, y, 1) of the image to the transformed coordinates.
+// Its last row is ignored and treated as (0, 0, 1).
+//
+// The resulting image is the bounding box of the source image and the transformed image,
+// so the translation moves the transformed image relative to the original position,
+// and areas not covered by the transformed image are transparent.
+// Returns nil, if the matrix is singular or identity.
+func Affine(m gm32.Mat3, interpolation Interpolation) MergingFilter {
+	m[6], m[7], m[8] = 0, 0, 1
+	if m == (gm32.Mat3{1, 0, 0, 0, 1, 0, 0, 0, 1}) {
+		return nil
+	}
+
+	if _, ok := m.Inverse(); !ok {
+		return nil
+	}
+
+	return &affineFilter{
+		m:                m,
+		interpolation:    interpolation,
+		oldInterpolation: interpolation,
+		mergeCount:       1,
+	}
+}
@@ -0,0 +1,273 @@
+package gft
+
+import (
+	"image"
+	"image/draw"
+
+	"github.com/infastin/gul/giu/gcu"
+	"github.com/infastin/gul/gm32"
+	"github.com/infastin/gul/tools"
+)
+
+// Specifies how colors of two layers are mixed.
+type BlendMode int
+
+const (
+	// The top layer is simply placed over the bottom one.
+	NormalBlend BlendMode = iota
+
+	MultiplyBlend
+	ScreenBlend
+	OverlayBlend
+	SoftLightBlend
+	HardLightBlend
+	DarkenBlend
+	LightenBlend
+	ColorDodgeBlend
+	ColorBurnBlend
+	DifferenceBlend
+	ExclusionBlend
+
+	// Hue of the top layer with saturation and luminosity of the bottom layer.
+	HueBlend
+
+	// Saturation of the top layer with hue and luminosity of the bottom layer.
+	SaturationBlend
+
+	// Hue and saturation of the top layer with luminosity of the bottom layer.
+	ColorBlend
+
+	// Luminosity of the top layer with hue and saturation of the bottom layer.
+	LuminosityBlend
+)
+
+// Mixes two color channel values using separable blend mode.
+func blendChannel(cb, cs float32, mode BlendMode) float32 {
+	switch mode {
+	case MultiplyBlend:
+		return cb * cs
+	case ScreenBlend:
+		return cb + cs - cb*cs
+	case OverlayBlend:
+		return blendChannel(cs, cb, HardLightBlend)
+	case SoftLightBlend:
+		if cs <= 0.5 {
+			return cb - (1-2*cs)*cb*(1-cb)
+		}
+
+		var d float32
+		if cb <= 0.25 {
+			d = ((16*cb-12)*cb + 4) * cb
+		} else {
+			d = gm32.Sqrt(cb)
+		}
+
+		return cb + (2*cs-1)*(d-cb)
+	case HardLightBlend:
+		if cs <= 0.5 {
+			return cb * 2 * cs
+		}
+		return blendChannel(cb, 2*cs-1, ScreenBlend)
+	case DarkenBlend:
+		return gm32.Min(cb, cs)
+	case LightenBlend:
+		return gm32.Max(cb, cs)
+	case ColorDodgeBlend:
+		if cb == 0 {
+			return 0
+		}
+		if cs >= 1 {
+			return 1
+		}
+		return gm32.Min(1, cb/(1-cs))
+	case ColorBurnBlend:
+		if cb >= 1 {
+			return 1
+		}
+		if cs <= 0 {
+			return 0
+		}
+		return 1 - gm32.Min(1, (1-cb)/cs)
+	case DifferenceBlend:
+		return gm32.Abs(cb - cs)
+	case ExclusionBlend:
+		return cb + cs - 2*cb*cs
+	}
+
+	return cs
+}
+
+// Moves the color into the RGB cube preserving its luminosity.
+func clipColor(r, g, b float32) (float32, float32, float32) {
+	l := gcu.RGBLuminance(r, g, b)
+	n := gm32.Min(r, gm32.Min(g, b))
+	x := gm32.Max(r, gm32.Max(g, b))
+
+	if n < 0 {
+		r = l + (r-l)*l/(l-n)
+		g = l + (g-l)*l/(l-n)
+		b = l + (b-l)*l/(l-n)
+	}
+
+	if x > 1 {
+		r = l + (r-l)*(1-l)/(x-l)
+		g = l + (g-l)*(1-l)/(x-l)
+		b = l + (b-l)*(1-l)/(x-l)
+	}
+
+	return r, g, b
+}
+
+func setLuminosity(r, g, b, l float32) (float32, float32, float32) {
+	d := l - gcu.RGBLuminance(r, g, b)
+	return clipColor(r+d, g+d, b+d)
+}
+
+func colorSaturation(r, g, b float32) float32 {
+	return gm32.Max(r, gm32.Max(g, b)) - gm32.Min(r, gm32.Min(g, b))
+}
+
+func setSaturation(r, g, b, s float32) (float32, float32, float32) {
+	c := [3]*float32{&r, &g, &b}
+
+	// Sort pointers to get minimal, middle and maximal channels.
+	if *c[0] > *c[1] {
+		c[0], c[1] = c[1], c[0]
+	}
+	if *c[1] > *c[2] {
+		c[1], c[2] = c[2], c[1]
+	}
+	if *c[0] > *c[1] {
+		c[0], c[1] = c[1], c[0]
+	}
+
+	min, mid, max := c[0], c[1], c[2]
+	if *max > *min {
+		*mid = (*mid - *min) * s / (*max - *min)
+		*max = s
+	} else {
+		*mid = 0
+		*max = 0
+	}
+	*min = 0
+
+	return r, g, b
+}
+
+// Mixes the backdrop (bottom) and the source (top) colors ignoring alpha.
+func blendColors(cb, cs Pixel, mode BlendMode) (r, g, b float32) {
+	switch mode {
+	case HueBlend:
+		r, g, b = setSaturation(cs.R, cs.G, cs.B, colorSaturation(cb.R, cb.G, cb.B))
+		return setLuminosity(r, g, b, gcu.RGBLuminance(cb.R, cb.G, cb.B))
+	case SaturationBlend:
+		r, g, b = setSaturation(cb.R, cb.G, cb.B, colorSaturation(cs.R, cs.G, cs.B))
+		return setLuminosity(r, g, b, gcu.RGBLuminance(cb.R, cb.G, cb.B))
+	case ColorBlend:
+		return setLuminosity(cs.R, cs.G, cs.B, gcu.RGBLuminance(cb.R, cb.G, cb.B))
+	case LuminosityBlend:
+		return setLuminosity(cb.R, cb.G, cb.B, gcu.RGBLuminance(cs.R, cs.G, cs.B))
+	}
+
+	r = blendChannel(cb.R, cs.R, mode)
+	g = blendChannel(cb.G, cs.G, mode)
+	b = blendChannel(cb.B, cs.B, mode)
+
+	return r, g, b
+}
+
+// Composites the top pixel over the bottom pixel using the given blend mode.
+// The opacity of the top pixel is multiplied by the opacity parameter.
+func blendPixels(bottom, top Pixel, mode BlendMode, opacity float32) Pixel {
+	as := top.A * opacity
+	if as <= 0 {
+		return bottom
+	}
+
+	ab := bottom.A
+	if ab <= 0 {
+		top.A = as
+		return top
+	}
+
+	r, g, b := blendColors(bottom, top, mode)
+
+	// Mix the blended color with the top color depending on the bottom alpha.
+	r = (1-ab)*top.R + ab*r
+	g = (1-ab)*top.G + ab*g
+	b = (1-ab)*top.B + ab*b
+
+	ao := as + ab*(1-as)
+	q := 1 / ao
+
+	pix := Pixel{
+		R: (as*r + (1-as)*ab*bottom.R) * q,
+		G: (as*g + (1-as)*ab*bottom.G) * q,
+		B: (as*b + (1-as)*ab*bottom.B) * q,
+		A: ao,
+	}
+
+	return pix.Clamp(0, 1)
+}
+
+type blendFilter struct {
+	top     image.Image
+	mode    BlendMode
+	opacity float32
+	offset  image.Point
+}
+
+func (f *blendFilter) Bounds(src image.Rectangle) image.Rectangle {
+	return src
+}
+
+func (f *blendFilter) Apply(dst draw.Image, src image.Image, parallel bool) {
+	srcb := src.Bounds()
+	dstb := dst.Bounds()
+	topb := f.top.Bounds()
+
+	pixGetter := newPixelGetter(src)
+	topGetter := newPixelGetter(f.top)
+	pixSetter := newPixelSetter(dst)
+
+	// Top image bounds in the src image coordinates.
+	layerb := topb.Sub(topb.Min).Add(srcb.Min).Add(f.offset)
+
+	procs := 1
+	if parallel {
+		procs = 0
+	}
+
+	tools.Parallelize(procs, srcb.Min.Y, srcb.Max.Y, 1, func(start, end int) {
+		for y := start; y < end; y++ {
+			for x := srcb.Min.X; x < srcb.Max.X; x++ {
+				pix := pixGetter.getPixel(x, y)
+
+				if (image.Point{x, y}).In(layerb) {
+					top := topGetter.getPixel(x-layerb.Min.X+topb.Min.X, y-layerb.Min.Y+topb.Min.Y)
+					pix = blendPixels(pix, top, f.mode, f.opacity)
+				}
+
+				pixSetter.setPixel(dstb.Min.X+x-srcb.Min.X, dstb.Min.Y+y-srcb.Min.Y, pix)
+			}
+		}
+	})
+}
+
+// Composites the top image over an image using the given blend mode.
+// The opacity parameter must be in the range [0, 1].
+// The offset parameter is the position of the top-left corner of the top image
+// relative to the top-left corner of an image.
+// The bounds of an image aren't changed, parts of the top image outside of them are discarded.
+func Blend(top image.Image, mode BlendMode, opacity float32, offset image.Point) Filter {
+	if top == nil || opacity <= 0 {
+		return nil
+	}
+
+	return &blendFilter{
+		top:     top,
+		mode:    mode,
+		opacity: gm32.Min(opacity, 1),
+		offset:  offset,
+	}
+}
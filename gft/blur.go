@@ -0,0 +1,346 @@
+package gft
+
+import (
+	"image"
+	"image/draw"
+	"math"
+
+	"github.com/infastin/gul/gm32"
+	"github.com/infastin/gul/gmu"
+	"github.com/infastin/gul/tools"
+)
+
+// Convolves the src segment with the kernel and writes the result to the dst segment.
+// Colors are premultiplied by alpha before convolution.
+// Out of range indices are clamped to the segment bounds.
+func convolveSegment(dst []Pixel, src []Pixel, kernel []float32) {
+	radius := len(kernel) / 2
+	last := len(src) - 1
+
+	for i := 0; i < len(dst); i++ {
+		var r, g, b, a float32
+		for j, weight := range kernel {
+			k := gmu.ClampInt(i+j-radius, 0, last)
+			col := src[k]
+			wa := col.A * weight
+			r += col.R * wa
+			g += col.G * wa
+			b += col.B * wa
+			a += wa
+		}
+
+		if a <= 0 {
+			dst[i] = Pixel{0, 0, 0, 0}
+			continue
+		}
+
+		q := 1 / a
+		dst[i] = Pixel{r * q, g * q, b * q, a}
+	}
+}
+
+// Convolves an image with the kernelX horizontally and with the kernelY vertically.
+// Both kernels must have odd lengths.
+func convolveSeparable(dst draw.Image, src image.Image, kernelX, kernelY []float32, parallel bool) {
+	srcb := src.Bounds()
+	dstb := dst.Bounds()
+
+	srcWidth := srcb.Dx()
+	srcHeight := srcb.Dy()
+
+	pixGetter := newPixelGetter(src)
+	pixSetter := newPixelSetter(dst)
+
+	procs := 1
+	if parallel {
+		procs = 0
+	}
+
+	tmp := make([]Pixel, srcWidth*srcHeight)
+
+	tools.Parallelize(procs, srcb.Min.Y, srcb.Max.Y, 1, func(start, end int) {
+		srcBuf := make([]Pixel, srcWidth)
+
+		for y := start; y < end; y++ {
+			i := (y - srcb.Min.Y) * srcWidth
+			pixGetter.getPixelRow(y, &srcBuf)
+			convolveSegment(tmp[i:i+srcWidth], srcBuf, kernelX)
+		}
+	})
+
+	tools.Parallelize(procs, srcb.Min.X, srcb.Max.X, 1, func(start, end int) {
+		srcBuf := make([]Pixel, srcHeight)
+		dstBuf := make([]Pixel, srcHeight)
+
+		for x := start; x < end; x++ {
+			for i := 0; i < srcHeight; i++ {
+				srcBuf[i] = tmp[i*srcWidth+x-srcb.Min.X]
+			}
+
+			convolveSegment(dstBuf, srcBuf, kernelY)
+			pixSetter.setPixelColumn(dstb.Min.X+x-srcb.Min.X, dstBuf)
+		}
+	})
+}
+
+func gaussianKernel(sigma float32) []float32 {
+	radius := int(gm32.Ceil(sigma * 3))
+	kernel := make([]float32, 2*radius+1)
+
+	var sum float32
+	for i := -radius; i <= radius; i++ {
+		x := float32(i)
+		weight := gm32.Exp(-(x * x) / (2 * sigma * sigma))
+		kernel[i+radius] = weight
+		sum += weight
+	}
+
+	for i := range kernel {
+		kernel[i] /= sum
+	}
+
+	return kernel
+}
+
+func boxKernel1D(radius int) []float32 {
+	size := 2*radius + 1
+	kernel := make([]float32, size)
+
+	weight := 1 / float32(size)
+	for i := range kernel {
+		kernel[i] = weight
+	}
+
+	return kernel
+}
+
+type gaussianBlurFilter struct {
+	sigma      float32
+	mergeCount uint
+}
+
+func (f *gaussianBlurFilter) Bounds(src image.Rectangle) image.Rectangle {
+	return src
+}
+
+func (f *gaussianBlurFilter) Apply(dst draw.Image, src image.Image, parallel bool) {
+	kernel := gaussianKernel(f.sigma)
+	convolveSeparable(dst, src, kernel, kernel, parallel)
+}
+
+func (f *gaussianBlurFilter) CanMerge(filter Filter) bool {
+	if _, ok := filter.(*gaussianBlurFilter); ok {
+		return true
+	}
+
+	return false
+}
+
+func (f *gaussianBlurFilter) Merge(filter Filter) {
+	filt := filter.(*gaussianBlurFilter)
+
+	f.sigma = gm32.Sqrt(f.sigma*f.sigma + filt.sigma*filt.sigma)
+	f.mergeCount++
+}
+
+func (f *gaussianBlurFilter) CanUndo(filter Filter) bool {
+	if _, ok := filter.(*gaussianBlurFilter); ok {
+		return true
+	}
+
+	return false
+}
+
+func (f *gaussianBlurFilter) Undo(filter Filter) bool {
+	filt := filter.(*gaussianBlurFilter)
+
+	f.sigma = gm32.Sqrt(gm32.Max(0, f.sigma*f.sigma-filt.sigma*filt.sigma))
+	f.mergeCount--
+
+	return f.mergeCount == 0
+}
+
+func (f *gaussianBlurFilter) Skip() bool {
+	return f.sigma == 0
+}
+
+func (f *gaussianBlurFilter) Copy() Filter {
+	return &gaussianBlurFilter{
+		sigma:      f.sigma,
+		mergeCount: f.mergeCount,
+	}
+}
+
+// Blurs an image using Gaussian function.
+// The sigma parameter must be positive and indicates how much the image will be blurred.
+// When merging, variances of the filters are summed.
+func GaussianBlur(sigma float32) MergingFilter {
+	if sigma <= 0 {
+		return nil
+	}
+
+	return &gaussianBlurFilter{
+		sigma:      sigma,
+		mergeCount: 1,
+	}
+}
+
+type boxBlurFilter struct {
+	radius int
+}
+
+func (f *boxBlurFilter) Bounds(src image.Rectangle) image.Rectangle {
+	return src
+}
+
+func (f *boxBlurFilter) Apply(dst draw.Image, src image.Image, parallel bool) {
+	kernel := boxKernel1D(f.radius)
+	convolveSeparable(dst, src, kernel, kernel, parallel)
+}
+
+func (f *boxBlurFilter) CanMerge(filter Filter) bool {
+	return false
+}
+
+func (f *boxBlurFilter) Merge(filter Filter) {}
+
+func (f *boxBlurFilter) CanUndo(filter Filter) bool {
+	if filt, ok := filter.(*boxBlurFilter); ok {
+		return f.radius == filt.radius
+	}
+
+	return false
+}
+
+func (f *boxBlurFilter) Undo(filter Filter) bool {
+	return true
+}
+
+func (f *boxBlurFilter) Skip() bool {
+	return f.radius == 0
+}
+
+func (f *boxBlurFilter) Copy() Filter {
+	return &boxBlurFilter{
+		radius: f.radius,
+	}
+}
+
+// Blurs an image by averaging each pixel with its neighbors
+// in a square of a given radius.
+// The radius parameter must be positive.
+func BoxBlur(radius int) MergingFilter {
+	if radius <= 0 {
+		return nil
+	}
+
+	return &boxBlurFilter{
+		radius: radius,
+	}
+}
+
+type motionBlurFilter struct {
+	rad    float32
+	length float32
+}
+
+func (f *motionBlurFilter) Bounds(src image.Rectangle) image.Rectangle {
+	return src
+}
+
+func (f *motionBlurFilter) Apply(dst draw.Image, src image.Image, parallel bool) {
+	srcb := src.Bounds()
+	dstb := dst.Bounds()
+
+	maxX := float32(srcb.Max.X - 1)
+	maxY := float32(srcb.Max.Y - 1)
+	minX := float32(srcb.Min.X)
+	minY := float32(srcb.Min.Y)
+
+	numSamples := int(gm32.Ceil(f.length)) + 1
+	sine, cosine := gm32.Sincos(f.rad)
+
+	stepX := cosine * f.length / float32(numSamples-1)
+	stepY := -sine * f.length / float32(numSamples-1)
+
+	startX := -stepX * float32(numSamples-1) / 2
+	startY := -stepY * float32(numSamples-1) / 2
+
+	pixGetter := newPixelGetter(src)
+	pixSetter := newPixelSetter(dst)
+
+	procs := 1
+	if parallel {
+		procs = 0
+	}
+
+	tools.Parallelize(procs, srcb.Min.Y, srcb.Max.Y, 1, func(start, end int) {
+		for y := start; y < end; y++ {
+			for x := srcb.Min.X; x < srcb.Max.X; x++ {
+				var r, g, b, a float32
+
+				for i := 0; i < numSamples; i++ {
+					sx := gm32.Clamp(float32(x)+startX+stepX*float32(i), minX, maxX)
+					sy := gm32.Clamp(float32(y)+startY+stepY*float32(i), minY, maxY)
+
+					pix := bilinearInterpolation(pixGetter, sx, sy)
+					r += pix.R * pix.A
+					g += pix.G * pix.A
+					b += pix.B * pix.A
+					a += pix.A
+				}
+
+				var pix Pixel
+				if a > 0 {
+					q := 1 / a
+					pix = Pixel{r * q, g * q, b * q, a / float32(numSamples)}
+				}
+
+				pixSetter.setPixel(dstb.Min.X+x-srcb.Min.X, dstb.Min.Y+y-srcb.Min.Y, pix)
+			}
+		}
+	})
+}
+
+func (f *motionBlurFilter) CanMerge(filter Filter) bool {
+	return false
+}
+
+func (f *motionBlurFilter) Merge(filter Filter) {}
+
+func (f *motionBlurFilter) CanUndo(filter Filter) bool {
+	if filt, ok := filter.(*motionBlurFilter); ok {
+		return f.rad == filt.rad && f.length == filt.length
+	}
+
+	return false
+}
+
+func (f *motionBlurFilter) Undo(filter Filter) bool {
+	return true
+}
+
+func (f *motionBlurFilter) Skip() bool {
+	return f.length < 1
+}
+
+func (f *motionBlurFilter) Copy() Filter {
+	return &motionBlurFilter{
+		rad:    f.rad,
+		length: f.length,
+	}
+}
+
+// Blurs an image along a line, imitating the motion of a camera.
+// The angle of the line is given in radians, the length is given in pixels.
+// The length parameter must be at least 1.
+func MotionBlur(rad, length float32) MergingFilter {
+	if length < 1 {
+		return nil
+	}
+
+	return &motionBlurFilter{
+		rad:    gm32.Mod(rad, math.Pi),
+		length: length,
+	}
+}
@@ -9,7 +9,7 @@ import (
 // Must be a pointer.
 type ColorFilter interface {
 	// Returns changed color.
-	Fn(pix pixel) pixel
+	Fn(pix Pixel) Pixel
 }
 
 // This color filter can merge other color filters into itself.
@@ -42,14 +42,14 @@ type MergingColorFilter interface {
 }
 
 type colorFilterFunc struct {
-	fn func(pix pixel) pixel
+	fn func(pix Pixel) Pixel
 }
 
-func (f *colorFilterFunc) Fn(pix pixel) pixel {
+func (f *colorFilterFunc) Fn(pix Pixel) Pixel {
 	return f.fn(pix)
 }
 
-func ColorFilterFunc(fn func(pix pixel) pixel) ColorFilter {
+func ColorFilterFunc(fn func(pix Pixel) Pixel) ColorFilter {
 	return &colorFilterFunc{
 		fn: fn,
 	}
@@ -93,7 +93,7 @@ func (f *sepiaFilter) Prepare() {
 	f.percentage = gm32.Clamp(f.percentage, 0, 100)
 }
 
-func (f *sepiaFilter) Fn(pix pixel) pixel {
+func (f *sepiaFilter) Fn(pix Pixel) Pixel {
 	rat := f.percentage / 100
 
 	rr := 1 - 0.607*rat
@@ -108,11 +108,11 @@ func (f *sepiaFilter) Fn(pix pixel) pixel {
 	bg := 0.534 * rat
 	bb := 1 - 0.869*rat
 
-	r := pix.r*rr + pix.g*rg + pix.b*rb
-	g := pix.r*gr + pix.g*gg + pix.b*gb
-	b := pix.r*br + pix.g*bg + pix.b*bb
+	r := pix.R*rr + pix.G*rg + pix.B*rb
+	g := pix.R*gr + pix.G*gg + pix.B*gb
+	b := pix.R*br + pix.G*bg + pix.B*bb
 
-	return pixel{r, g, b, pix.a}
+	return Pixel{r, g, b, pix.A}
 }
 
 func (f *sepiaFilter) Copy() ColorFilter {
@@ -189,19 +189,19 @@ func (f *hsbFilter) Prepare() {
 	f.b = gm32.Clamp(f.b, -100, 100)
 }
 
-func (f *hsbFilter) Fn(pix pixel) pixel {
+func (f *hsbFilter) Fn(pix Pixel) Pixel {
 	h0 := f.h / 360
 	s0 := f.s / 100
 	b0 := f.b / 100
 
-	h1, s1, v1 := gcu.RGBToHSV(pix.r, pix.g, pix.b)
+	h1, s1, v1 := gcu.RGBToHSV(pix.R, pix.G, pix.B)
 
 	h2 := gm32.Clamp(h1+h0, 0, 1)
 	s2 := gm32.Clamp(s1+s0, 0, 1)
 	v2 := gm32.Clamp(v1+b0, 0, 1)
 
 	r, g, b := gcu.HSVToRGB(h2, s2, v2)
-	return pixel{r, g, b, pix.a}
+	return Pixel{r, g, b, pix.A}
 }
 
 // Changes HSB of each color in the image.
@@ -274,19 +274,19 @@ func (f *hslFilter) Prepare() {
 	f.l = gm32.Clamp(f.l, -100, 100)
 }
 
-func (f *hslFilter) Fn(pix pixel) pixel {
+func (f *hslFilter) Fn(pix Pixel) Pixel {
 	h0 := f.h / 360
 	s0 := f.s / 100
 	l0 := f.l / 100
 
-	h1, s1, l1 := gcu.RGBToHSL(pix.r, pix.g, pix.b)
+	h1, s1, l1 := gcu.RGBToHSL(pix.R, pix.G, pix.B)
 
 	h2 := gm32.Clamp(h1+h0, 0, 1)
 	s2 := gm32.Clamp(s1+s0, 0, 1)
 	l2 := gm32.Clamp(l1+l0, 0, 1)
 
 	r, g, b := gcu.HSLToRGB(h2, s2, l2)
-	return pixel{r, g, b, pix.a}
+	return Pixel{r, g, b, pix.A}
 }
 
 // Changes HSL of each color in the image.
@@ -421,19 +421,19 @@ func (f *colorBalanceFilter) Prepare() {
 	f.highlights = f.highlights.Clamp()
 }
 
-func (f *colorBalanceFilter) Fn(pix pixel) pixel {
-	_, _, l := gcu.RGBToHSL(pix.r, pix.g, pix.b)
+func (f *colorBalanceFilter) Fn(pix Pixel) Pixel {
+	_, _, l := gcu.RGBToHSL(pix.R, pix.G, pix.B)
 
-	rn := f.mask(pix.r, l, f.shadows.CyanRed, f.midtones.CyanRed, f.highlights.CyanRed)
-	gn := f.mask(pix.g, l, f.shadows.MagentaGreen, f.midtones.MagentaGreen, f.highlights.MagentaGreen)
-	bn := f.mask(pix.b, l, f.shadows.YellowBlue, f.midtones.YellowBlue, f.highlights.YellowBlue)
+	rn := f.mask(pix.R, l, f.shadows.CyanRed, f.midtones.CyanRed, f.highlights.CyanRed)
+	gn := f.mask(pix.G, l, f.shadows.MagentaGreen, f.midtones.MagentaGreen, f.highlights.MagentaGreen)
+	bn := f.mask(pix.B, l, f.shadows.YellowBlue, f.midtones.YellowBlue, f.highlights.YellowBlue)
 
 	if f.preserveLuminosity {
 		h2, s2, _ := gcu.RGBToHSL(rn, gn, bn)
 		rn, gn, bn = gcu.HSLToRGB(h2, s2, l)
 	}
 
-	return pixel{rn, gn, bn, pix.a}
+	return Pixel{rn, gn, bn, pix.A}
 }
 
 // Adjusts color distribution in an image.
@@ -507,12 +507,12 @@ func (f *colorizeFilter) Prepare() {
 }
 
 // https://github.com/GNOME/gimp/blob/708f075f804caa5cbd11cae2f85f3726456aedcb/app/operations/gimpoperationcolorize.c#L222
-func (f *colorizeFilter) Fn(pix pixel) pixel {
+func (f *colorizeFilter) Fn(pix Pixel) Pixel {
 	h := f.h / 360
 	s := f.s / 100
 	l := f.l / 100
 
-	lum := gcu.RGBLuminance(pix.r, pix.g, pix.b)
+	lum := gcu.RGBLuminance(pix.R, pix.G, pix.B)
 
 	switch {
 	case l > 0:
@@ -523,7 +523,7 @@ func (f *colorizeFilter) Fn(pix pixel) pixel {
 	}
 
 	r, g, b := gcu.HSLToRGB(h, s, lum)
-	return pixel{r, g, b, pix.a}
+	return Pixel{r, g, b, pix.A}
 }
 
 // Colorizes an image.
@@ -538,12 +538,40 @@ func Colorize(h, s, l float32) MergingColorFilter {
 	}
 }
 
+type gradientMapFilter struct {
+	ramp *colorRamp
+}
+
+func (f *gradientMapFilter) Fn(pix Pixel) Pixel {
+	lum := gcu.RGBLuminance(pix.R, pix.G, pix.B)
+
+	res := f.ramp.at(lum)
+	res.A *= pix.A
+
+	return res
+}
+
+// Maps the luminance of each color in the image to the color of a gradient,
+// where black is mapped to the offset 0 and white is mapped to the offset 1.
+// The space parameter specifies how colors are interpolated between stops.
+// Returns nil, if there are no stops.
+func GradientMap(stops []GradientStop, space GradientSpace) ColorFilter {
+	ramp := newColorRamp(stops, space)
+	if ramp == nil {
+		return nil
+	}
+
+	return &gradientMapFilter{
+		ramp: ramp,
+	}
+}
+
 var (
 	// Grayscales an image.
 	Grayscale ColorFilter = ColorFilterFunc(grayscale)
 )
 
-func grayscale(pix pixel) pixel {
-	v := 0.299*pix.r + 0.587*pix.g + 0.114*pix.b
-	return pixel{v, v, v, pix.a}
+func grayscale(pix Pixel) Pixel {
+	v := 0.299*pix.R + 0.587*pix.G + 0.114*pix.B
+	return Pixel{v, v, v, pix.A}
 }
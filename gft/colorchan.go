@@ -17,6 +17,45 @@ type ColorchanFilter interface {
 	UseLut() bool
 }
 
+// Color channel of an image.
+type Channel int
+
+const (
+	RedChannel Channel = iota
+	GreenChannel
+	BlueChannel
+	AlphaChannel
+)
+
+// Set of color channels.
+type ChannelMask uint8
+
+const (
+	RedMask ChannelMask = 1 << iota
+	GreenMask
+	BlueMask
+	AlphaMask
+
+	RGBMask  = RedMask | GreenMask | BlueMask
+	RGBAMask = RGBMask | AlphaMask
+)
+
+// Returns true, if the mask contains the given channel.
+// Otherwise, returns false.
+func (m ChannelMask) Has(ch Channel) bool {
+	return m&(1<<ch) != 0
+}
+
+// This colorchan filter can change each color channel differently.
+// Unlike ordinary colorchan filters, it can also change the alpha channel.
+// Fn is used only when the filter is applied outside of CombineColorchanFilters.
+type ChannelwiseColorchanFilter interface {
+	ColorchanFilter
+
+	// Returns changed value of the given color channel.
+	ChannelFn(x float32, ch Channel) float32
+}
+
 // This colorhan filter can merge other colorhan filters into itself.
 type MergingColorchanFilter interface {
 	ColorchanFilter
@@ -332,3 +371,113 @@ func Brightness(perc float32) MergingColorchanFilter {
 		brightness: perc,
 	}
 }
+
+type channelsFilter struct {
+	filter ColorchanFilter
+	mask   ChannelMask
+}
+
+func (f *channelsFilter) CanMerge(filter ColorchanFilter) bool {
+	filt, ok := filter.(*channelsFilter)
+	if !ok || f.mask != filt.mask {
+		return false
+	}
+
+	if fi, ok := f.filter.(MergingColorchanFilter); ok {
+		return fi.CanMerge(filt.filter)
+	}
+
+	return false
+}
+
+func (f *channelsFilter) Merge(filter ColorchanFilter) {
+	filt := filter.(*channelsFilter)
+	f.filter.(MergingColorchanFilter).Merge(filt.filter)
+}
+
+func (f *channelsFilter) CanUndo(filter ColorchanFilter) bool {
+	filt, ok := filter.(*channelsFilter)
+	if !ok || f.mask != filt.mask {
+		return false
+	}
+
+	if fi, ok := f.filter.(MergingColorchanFilter); ok {
+		return fi.CanUndo(filt.filter)
+	}
+
+	return false
+}
+
+func (f *channelsFilter) Undo(filter ColorchanFilter) {
+	filt := filter.(*channelsFilter)
+	f.filter.(MergingColorchanFilter).Undo(filt.filter)
+}
+
+func (f *channelsFilter) Skip() bool {
+	if fi, ok := f.filter.(MergingColorchanFilter); ok {
+		return fi.Skip()
+	}
+
+	return false
+}
+
+func (f *channelsFilter) Copy() ColorchanFilter {
+	filter := f.filter
+	if fi, ok := filter.(MergingColorchanFilter); ok {
+		filter = fi.Copy()
+	}
+
+	return &channelsFilter{
+		filter: filter,
+		mask:   f.mask,
+	}
+}
+
+func (f *channelsFilter) Prepare() {
+	if fi, ok := f.filter.(MergingColorchanFilter); ok {
+		fi.Prepare()
+	}
+}
+
+// Fn is applied equally to all color channels,
+// so the filter is applied only if the mask has all of them.
+// Combined filters use ChannelFn instead.
+func (f *channelsFilter) Fn(x float32) float32 {
+	if f.mask&RGBMask != RGBMask {
+		return x
+	}
+
+	return f.filter.Fn(x)
+}
+
+func (f *channelsFilter) ChannelFn(x float32, ch Channel) float32 {
+	if !f.mask.Has(ch) {
+		return x
+	}
+
+	if fi, ok := f.filter.(ChannelwiseColorchanFilter); ok {
+		return fi.ChannelFn(x, ch)
+	}
+
+	return f.filter.Fn(x)
+}
+
+func (f *channelsFilter) UseLut() bool {
+	return f.filter.UseLut()
+}
+
+// Applies the colorchan filter only to the channels in the mask.
+// Unlike other colorchan filters, it can also change the alpha channel.
+// Example: Channels(Invert(), AlphaMask) inverts only the alpha channel.
+//
+// Filters with the same mask are merged and undone by the wrapped filter.
+func Channels(filter ColorchanFilter, mask ChannelMask) MergingColorchanFilter {
+	if filter == nil || mask&RGBAMask == 0 {
+		return nil
+	}
+
+	return &channelsFilter{
+		filter: filter,
+		mask:   mask & RGBAMask,
+	}
+}
@@ -10,7 +10,7 @@ import (
 
 type combineColorchanFilter struct {
 	filters    []ColorchanFilter
-	luts       [][]float32
+	luts       [][][]float32
 	mergeCount uint
 }
 
@@ -129,6 +129,7 @@ func (f *combineColorchanFilter) Skip() bool {
 
 func (f *combineColorchanFilter) Copy() Filter {
 	r := &combineColorchanFilter{
+		luts:       make([][][]float32, len(f.filters)),
 		mergeCount: f.mergeCount,
 	}
 
@@ -154,28 +155,45 @@ func (f *combineColorchanFilter) Bounds(src image.Rectangle) image.Rectangle {
 }
 
 func (f *combineColorchanFilter) makeLut(lutSize int, index int) {
-	lutLen := len(f.luts[index])
-	start := 0
-
-	if lutLen == 0 {
-		f.luts[index] = make([]float32, lutSize)
-	} else if lutLen < lutSize {
-		newLut := make([]float32, lutSize)
-		copy(newLut[:lutLen], f.luts[index])
-		f.luts[index] = newLut
-		start = lutLen
+	filt := f.filters[index]
+	chanFilt, channelwise := filt.(ChannelwiseColorchanFilter)
+
+	numLuts := 1
+	if channelwise {
+		numLuts = 4
+	}
+
+	if len(f.luts[index]) != numLuts {
+		f.luts[index] = make([][]float32, numLuts)
 	}
 
 	q := float32(1) / float32(lutSize-1)
-	for i := start; i < lutSize; i++ {
-		v := float32(i) * q
-		f.luts[index][i] = f.filters[index].Fn(v)
+	for ch := 0; ch < numLuts; ch++ {
+		lut := f.luts[index][ch]
+		if len(lut) != lutSize {
+			lut = make([]float32, lutSize)
+			f.luts[index][ch] = lut
+		}
+
+		for i := 0; i < lutSize; i++ {
+			v := float32(i) * q
+			if channelwise {
+				lut[i] = chanFilt.ChannelFn(v, Channel(ch))
+			} else {
+				lut[i] = filt.Fn(v)
+			}
+		}
 	}
 }
 
-func (f *combineColorchanFilter) getFromLut(x float32, index int) float32 {
-	i := int(gm32.Round(x * float32(len(f.luts[index])-1)))
-	return f.luts[index][i]
+func (f *combineColorchanFilter) getFromLut(x float32, index int, ch Channel) float32 {
+	lut := f.luts[index][0]
+	if len(f.luts[index]) > 1 {
+		lut = f.luts[index][ch]
+	}
+
+	i := int(gm32.Round(gm32.Clamp(x, 0, 1) * float32(len(lut)-1)))
+	return lut[i]
 }
 
 func (f *combineColorchanFilter) Apply(dst draw.Image, src image.Image, parallel bool) {
@@ -186,6 +204,7 @@ func (f *combineColorchanFilter) Apply(dst draw.Image, src image.Image, parallel
 	pixSetter := newPixelSetter(dst)
 
 	useLut := make([]bool, len(f.filters))
+	chanFilters := make([]ChannelwiseColorchanFilter, len(f.filters))
 
 	for i, filt := range f.filters {
 		if filt == nil {
@@ -196,8 +215,11 @@ func (f *combineColorchanFilter) Apply(dst draw.Image, src image.Image, parallel
 			filt.Prepare()
 		}
 
+		if filt, ok := filt.(ChannelwiseColorchanFilter); ok {
+			chanFilters[i] = filt
+		}
+
 		if filt.UseLut() {
-			lutSize := len(f.luts[i])
 			neededLutSize := 0
 
 			switch pixGetter.img.(type) {
@@ -209,10 +231,7 @@ func (f *combineColorchanFilter) Apply(dst draw.Image, src image.Image, parallel
 
 			numCalc := srcb.Dx() * srcb.Dy() * 3
 			if numCalc > neededLutSize*2 {
-				if lutSize != neededLutSize {
-					f.makeLut(neededLutSize, i)
-				}
-
+				f.makeLut(neededLutSize, i)
 				useLut[i] = true
 			}
 		} else {
@@ -235,14 +254,23 @@ func (f *combineColorchanFilter) Apply(dst draw.Image, src image.Image, parallel
 						continue
 					}
 
-					if useLut[i] {
-						pix.r = f.getFromLut(pix.r, i)
-						pix.g = f.getFromLut(pix.g, i)
-						pix.b = f.getFromLut(pix.b, i)
-					} else {
-						pix.r = filt.Fn(pix.r)
-						pix.g = filt.Fn(pix.g)
-						pix.b = filt.Fn(pix.b)
+					switch {
+					case useLut[i]:
+						pix.R = f.getFromLut(pix.R, i, RedChannel)
+						pix.G = f.getFromLut(pix.G, i, GreenChannel)
+						pix.B = f.getFromLut(pix.B, i, BlueChannel)
+						if chanFilters[i] != nil {
+							pix.A = f.getFromLut(pix.A, i, AlphaChannel)
+						}
+					case chanFilters[i] != nil:
+						pix.R = chanFilters[i].ChannelFn(pix.R, RedChannel)
+						pix.G = chanFilters[i].ChannelFn(pix.G, GreenChannel)
+						pix.B = chanFilters[i].ChannelFn(pix.B, BlueChannel)
+						pix.A = chanFilters[i].ChannelFn(pix.A, AlphaChannel)
+					default:
+						pix.R = filt.Fn(pix.R)
+						pix.G = filt.Fn(pix.G)
+						pix.B = filt.Fn(pix.B)
 					}
 				}
 
@@ -271,7 +299,7 @@ func CombineColorchanFilters(filters ...ColorchanFilter) MergingFilter {
 
 	return &combineColorchanFilter{
 		filters:    filters,
-		luts:       make([][]float32, len(filters)),
+		luts:       make([][][]float32, len(filters)),
 		mergeCount: 1,
 	}
 }
@@ -0,0 +1,155 @@
+package gft
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+
+	"github.com/infastin/gul/gm32"
+	"github.com/infastin/gul/tools"
+)
+
+type convolutionFilter struct {
+	kernel        []float32
+	width, height int
+	alpha         bool
+	edge          EdgeMode
+}
+
+func (f *convolutionFilter) Bounds(src image.Rectangle) image.Rectangle {
+	return src
+}
+
+func (f *convolutionFilter) Apply(dst draw.Image, src image.Image, parallel bool) {
+	srcb := src.Bounds()
+	dstb := dst.Bounds()
+
+	halfWidth := f.width / 2
+	halfHeight := f.height / 2
+
+	pixGetter := newEdgePixelGetter(src, f.edge)
+	pixSetter := newPixelSetter(dst)
+
+	procs := 1
+	if parallel {
+		procs = 0
+	}
+
+	tools.Parallelize(procs, srcb.Min.Y, srcb.Max.Y, 1, func(start, end int) {
+		for y := start; y < end; y++ {
+			for x := srcb.Min.X; x < srcb.Max.X; x++ {
+				var r, g, b, a float32
+
+				for ky := 0; ky < f.height; ky++ {
+					for kx := 0; kx < f.width; kx++ {
+						weight := f.kernel[kx+ky*f.width]
+						if weight == 0 {
+							continue
+						}
+
+						pix := pixGetter.getPixel(x+kx-halfWidth, y+ky-halfHeight)
+						if f.alpha {
+							wa := pix.A * weight
+							r += pix.R * wa
+							g += pix.G * wa
+							b += pix.B * wa
+							a += wa
+						} else {
+							r += pix.R * weight
+							g += pix.G * weight
+							b += pix.B * weight
+						}
+					}
+				}
+
+				var pix Pixel
+				if f.alpha {
+					if a > 0 {
+						q := 1 / a
+						pix = Pixel{r * q, g * q, b * q, a}
+					}
+				} else {
+					pix = Pixel{r, g, b, pixGetter.getPixel(x, y).A}
+				}
+
+				pixSetter.setPixel(dstb.Min.X+x-srcb.Min.X, dstb.Min.Y+y-srcb.Min.Y, pix.Clamp(0, 1))
+			}
+		}
+	})
+}
+
+// Applies a convolution kernel to an image.
+// The kernel must have odd number of rows and columns.
+//
+// If normalize is true, the kernel will be divided by the sum of its elements
+// (if the sum is not zero).
+// If alpha is true, the alpha channel will be convolved too.
+// Otherwise, the alpha channel will be left unchanged.
+// The alpha channel is also left unchanged, if the sum of the kernel elements is not positive after normalization
+// (e.g. edge detection kernels), because such kernels make the convolved alpha zero.
+// The edge parameter specifies how pixels outside of the image are obtained.
+// Returns nil, if the kernel is nil.
+func Convolution(kernel *gm32.Mat, normalize, alpha bool, edge EdgeMode) Filter {
+	if kernel == nil {
+		return nil
+	}
+
+	if kernel.M%2 == 0 || kernel.N%2 == 0 {
+		err := fmt.Errorf("the kernel must have odd number of rows and columns (got (%dx%d))", kernel.M, kernel.N)
+		panic(err)
+	}
+
+	data := make([]float32, len(kernel.Data))
+	copy(data, kernel.Data)
+
+	var sum float32
+	for _, v := range data {
+		sum += v
+	}
+
+	if normalize && sum != 0 {
+		for i := range data {
+			data[i] /= sum
+		}
+		sum = 1
+	}
+
+	if sum <= 0 {
+		alpha = false
+	}
+
+	return &convolutionFilter{
+		kernel: data,
+		width:  kernel.N,
+		height: kernel.M,
+		alpha:  alpha,
+		edge:   edge,
+	}
+}
+
+// Returns a kernel for Convolution, which makes an image look embossed.
+func EmbossKernel() *gm32.Mat {
+	return gm32.NewMat(3, 3)(
+		-2, -1, 0,
+		-1, 1, 1,
+		0, 1, 2,
+	)
+}
+
+// Returns a kernel for Convolution, which highlights edges in an image.
+func EdgeDetectKernel() *gm32.Mat {
+	return gm32.NewMat(3, 3)(
+		-1, -1, -1,
+		-1, 8, -1,
+		-1, -1, -1,
+	)
+}
+
+// Returns a kernel for Convolution, which sharpens an image.
+func SharpenKernel() *gm32.Mat {
+	return gm32.NewMat(3, 3)(
+		0, -1, 0,
+		-1, 5, -1,
+		0, -1, 0,
+	)
+}
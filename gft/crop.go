@@ -221,3 +221,196 @@ func CropEllipse(cx, cy, rx, ry float32) Filter {
 		ry: ry,
 	}
 }
+
+type cropShapeFilter struct {
+	mask *MaskBuilder
+}
+
+// Returns the bounds of the shape in an image of the given size.
+func (f *cropShapeFilter) shapeBounds(src image.Rectangle) image.Rectangle {
+	width, height := src.Dx(), src.Dy()
+	return f.mask.shapesBounds(width, height).Intersect(image.Rect(0, 0, width, height))
+}
+
+func (f *cropShapeFilter) Bounds(src image.Rectangle) image.Rectangle {
+	shapeb := f.shapeBounds(src)
+	return image.Rect(0, 0, shapeb.Dx(), shapeb.Dy())
+}
+
+func (f *cropShapeFilter) Apply(dst draw.Image, src image.Image, parallel bool) {
+	cropWithMask(dst, src, f.mask, f.shapeBounds(src.Bounds()), parallel)
+}
+
+// Crops the rect rectangle of the src image, which is relative to the top-left corner of the image,
+// and makes the pixels outside of the mask transparent.
+func cropWithMask(dst draw.Image, src image.Image, mask *MaskBuilder, rect image.Rectangle, parallel bool) {
+	srcb := src.Bounds()
+	dstb := dst.Bounds()
+
+	alpha := image.NewAlpha(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	mask.rasterize(alpha, srcb.Dx(), srcb.Dy(), rect.Min)
+
+	pixGetter := newPixelGetter(src)
+	pixSetter := newPixelSetter(dst)
+
+	procs := 1
+	if parallel {
+		procs = 0
+	}
+
+	tools.Parallelize(procs, dstb.Min.Y, dstb.Max.Y, 1, func(start, end int) {
+		for yi := start; yi < end; yi++ {
+			for xi := dstb.Min.X; xi < dstb.Max.X; xi++ {
+				x := xi - dstb.Min.X
+				y := yi - dstb.Min.Y
+
+				pix := pixGetter.getPixel(srcb.Min.X+rect.Min.X+x, srcb.Min.Y+rect.Min.Y+y)
+				pix.A *= float32(alpha.AlphaAt(x, y).A) * qf8
+
+				pixSetter.setPixel(xi, yi, pix)
+			}
+		}
+	})
+}
+
+// Crops an image with a path.
+// The result is the bounding box of the path, and the pixels outside of the path are transparent.
+// The coordinates of the path must be in the range [0, 1].
+func CropPath(path *Path) Filter {
+	if path == nil || len(path.cmds) == 0 {
+		return nil
+	}
+
+	return &cropShapeFilter{
+		mask: NewMaskBuilder().Path(path),
+	}
+}
+
+// Crops an image with a polygon with the given vertices.
+// The result is the bounding box of the polygon, and the pixels outside of the polygon are transparent.
+// The coordinates of the vertices must be in the range [0, 1].
+// The polygon must have at least 3 vertices.
+func CropPolygon(points []gm32.Vec2) Filter {
+	if len(points) < 3 {
+		return nil
+	}
+
+	return &cropShapeFilter{
+		mask: NewMaskBuilder().Polygon(points),
+	}
+}
+
+type cropRoundedRectangleFilter struct {
+	// The part of an image, which is left after cropping.
+	// Initially, it is the bounding box of the rounded rectangle,
+	// but it can be reduced by merging rectangle crops.
+	window cropRectangleFilter
+
+	startX, startY float32
+	width, height  float32
+	radius         float32
+	mask           *MaskBuilder
+}
+
+func (f *cropRoundedRectangleFilter) Bounds(src image.Rectangle) image.Rectangle {
+	return f.window.Bounds(src)
+}
+
+func (f *cropRoundedRectangleFilter) Apply(dst draw.Image, src image.Image, parallel bool) {
+	srcb := src.Bounds()
+	dstb := f.window.Bounds(srcb)
+
+	startX := int(gm32.Floor(float32(srcb.Dx()) * f.window.startX))
+	startY := int(gm32.Floor(float32(srcb.Dy()) * f.window.startY))
+
+	rect := dstb.Add(image.Point{startX, startY})
+	cropWithMask(dst, src, f.mask, rect, parallel)
+}
+
+func (f *cropRoundedRectangleFilter) CanMerge(filter Filter) bool {
+	if _, ok := filter.(*cropRectangleFilter); ok {
+		return true
+	}
+
+	return false
+}
+
+func (f *cropRoundedRectangleFilter) Merge(filter Filter) {
+	f.window.Merge(filter)
+}
+
+func (f *cropRoundedRectangleFilter) CanUndo(filter Filter) bool {
+	switch filt := filter.(type) {
+	case *cropRectangleFilter:
+		return f.window.mergeCount > 1
+	case *cropRoundedRectangleFilter:
+		return f.window.mergeCount == 1 &&
+			f.startX == filt.startX && f.startY == filt.startY &&
+			f.width == filt.width && f.height == filt.height &&
+			f.radius == filt.radius
+	}
+
+	return false
+}
+
+func (f *cropRoundedRectangleFilter) Undo(filter Filter) bool {
+	if _, ok := filter.(*cropRoundedRectangleFilter); ok {
+		return true
+	}
+
+	f.window.Undo(filter)
+	return false
+}
+
+func (f *cropRoundedRectangleFilter) Skip() bool {
+	return false
+}
+
+func (f *cropRoundedRectangleFilter) Copy() Filter {
+	return &cropRoundedRectangleFilter{
+		window: f.window,
+		startX: f.startX,
+		startY: f.startY,
+		width:  f.width,
+		height: f.height,
+		radius: f.radius,
+		mask:   f.mask,
+	}
+}
+
+// Crops an image with an anti-aliased rounded rectangle starting at a given position (startX, startY)
+// with a given size (width, height) and with corners of a given radius.
+// The position and size parameters must be in the range [0, 1].
+// The radius is relative to the smaller side of an image and must be in the range [0, 0.5].
+// Example: CropRoundedRectangle(0, 0, 1, 1, 0.5) makes a round avatar from a square image.
+//
+// Subsequent rectangle crops are merged into the filter.
+func CropRoundedRectangle(startX, startY, width, height, radius float32) MergingFilter {
+	radius = gm32.Clamp(radius, 0, 0.5)
+
+	window := CropRectangle(startX, startY, width, height)
+	if window == nil {
+		if radius == 0 {
+			return nil
+		}
+
+		window = &cropRectangleFilter{
+			width:      1,
+			height:     1,
+			mergeCount: 1,
+		}
+	}
+
+	rect := window.(*cropRectangleFilter)
+	rect.mergeCount = 1
+
+	return &cropRoundedRectangleFilter{
+		window: *rect,
+		startX: rect.startX,
+		startY: rect.startY,
+		width:  rect.width,
+		height: rect.height,
+		radius: radius,
+		mask:   NewMaskBuilder().RoundedRectangle(rect.startX, rect.startY, rect.width, rect.height, radius),
+	}
+}
@@ -0,0 +1,159 @@
+package gft
+
+import (
+	"sort"
+
+	"github.com/infastin/gul/gm32"
+)
+
+// Monotone cubic spline (Fritsch-Carlson method).
+// It doesn't overshoot between the control points,
+// so monotonic control points give a monotonic curve.
+type monotoneSpline struct {
+	xs, ys []float32
+	ms     []float32
+}
+
+func newMonotoneSpline(points []gm32.Vec2) *monotoneSpline {
+	pts := make([]gm32.Vec2, len(points))
+	for i, p := range points {
+		pts[i] = gm32.Vec2{gm32.Clamp(p[0], 0, 1), gm32.Clamp(p[1], 0, 1)}
+	}
+
+	// The sort is stable, so points with the same x coordinate keep their order.
+	sort.SliceStable(pts, func(i, j int) bool {
+		return pts[i][0] < pts[j][0]
+	})
+
+	s := &monotoneSpline{}
+	for _, p := range pts {
+		x, y := p[0], p[1]
+
+		// Points with the same x coordinate are replaced by the last one.
+		if n := len(s.xs); n != 0 && s.xs[n-1] == x {
+			s.ys[n-1] = y
+			continue
+		}
+
+		s.xs = append(s.xs, x)
+		s.ys = append(s.ys, y)
+	}
+
+	n := len(s.xs)
+	if n < 2 {
+		return s
+	}
+
+	deltas := make([]float32, n-1)
+	for i := 0; i < n-1; i++ {
+		deltas[i] = (s.ys[i+1] - s.ys[i]) / (s.xs[i+1] - s.xs[i])
+	}
+
+	s.ms = make([]float32, n)
+	s.ms[0] = deltas[0]
+	s.ms[n-1] = deltas[n-2]
+
+	for i := 1; i < n-1; i++ {
+		if deltas[i-1]*deltas[i] <= 0 {
+			s.ms[i] = 0
+		} else {
+			s.ms[i] = (deltas[i-1] + deltas[i]) / 2
+		}
+	}
+
+	for i := 0; i < n-1; i++ {
+		if deltas[i] == 0 {
+			s.ms[i] = 0
+			s.ms[i+1] = 0
+			continue
+		}
+
+		a := s.ms[i] / deltas[i]
+		b := s.ms[i+1] / deltas[i]
+
+		if h := a*a + b*b; h > 9 {
+			t := 3 / gm32.Sqrt(h)
+			s.ms[i] = t * a * deltas[i]
+			s.ms[i+1] = t * b * deltas[i]
+		}
+	}
+
+	return s
+}
+
+func (s *monotoneSpline) at(x float32) float32 {
+	n := len(s.xs)
+
+	switch {
+	case n == 0:
+		return x
+	case n == 1:
+		return s.ys[0]
+	case x <= s.xs[0]:
+		return s.ys[0]
+	case x >= s.xs[n-1]:
+		return s.ys[n-1]
+	}
+
+	i := sort.Search(n, func(i int) bool {
+		return s.xs[i] > x
+	}) - 1
+
+	h := s.xs[i+1] - s.xs[i]
+	t := (x - s.xs[i]) / h
+	t2 := t * t
+	t3 := t2 * t
+
+	h00 := 2*t3 - 3*t2 + 1
+	h10 := t3 - 2*t2 + t
+	h01 := -2*t3 + 3*t2
+	h11 := t3 - t2
+
+	y := h00*s.ys[i] + h10*h*s.ms[i] + h01*s.ys[i+1] + h11*h*s.ms[i+1]
+	return gm32.Clamp(y, 0, 1)
+}
+
+type curvesFilter struct {
+	master *monotoneSpline
+	chans  [3]*monotoneSpline
+}
+
+func (f *curvesFilter) Fn(x float32) float32 {
+	return f.master.at(x)
+}
+
+func (f *curvesFilter) ChannelFn(x float32, ch Channel) float32 {
+	if ch == AlphaChannel {
+		return x
+	}
+
+	return f.chans[ch].at(f.master.at(x))
+}
+
+func (f *curvesFilter) UseLut() bool {
+	return true
+}
+
+// Adjusts tones of an image using curves.
+// Each curve is a monotone cubic spline going through the given control points.
+// The coordinates of the points must be in the range [0, 1]:
+// x is an input value and y is an output value.
+//
+// The master curve is applied to every color channel,
+// then the r, g and b curves are applied to the corresponding channels.
+// Empty curve leaves channel unchanged.
+// Curve with the only point gives a constant value.
+func Curves(master, r, g, b []gm32.Vec2) ChannelwiseColorchanFilter {
+	if len(master) == 0 && len(r) == 0 && len(g) == 0 && len(b) == 0 {
+		return nil
+	}
+
+	return &curvesFilter{
+		master: newMonotoneSpline(master),
+		chans: [3]*monotoneSpline{
+			newMonotoneSpline(r),
+			newMonotoneSpline(g),
+			newMonotoneSpline(b),
+		},
+	}
+}
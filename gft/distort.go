@@ -0,0 +1,253 @@
+package gft
+
+import (
+	"image"
+	"image/draw"
+	"math"
+
+	"github.com/infastin/gul/gm32"
+	"github.com/infastin/gul/tools"
+)
+
+// Draws the src image to the dst image of the same size, where each pixel of the dst image
+// is taken from the position in the src image returned by the fn function.
+// The coordinates are relative to the size of the images: (0, 0) is the top-left corner and
+// (1, 1) is the bottom-right corner.
+func inverseMap(
+	dst draw.Image, src image.Image,
+	fn func(x, y float32) (sx, sy float32),
+	interpolation Interpolation, edge EdgeMode, parallel bool,
+) {
+	srcb := src.Bounds()
+	dstb := dst.Bounds()
+
+	srcWidth := float32(srcb.Dx())
+	srcHeight := float32(srcb.Dy())
+	dstWidth := float32(dstb.Dx())
+	dstHeight := float32(dstb.Dy())
+
+	// If points outside of the image are transparent,
+	// edge pixels are only used for interpolation near the edges.
+	getterEdge := edge
+	if edge == EdgeTransparent {
+		getterEdge = EdgeClamp
+	}
+
+	pixGetter := newEdgePixelGetter(src, getterEdge)
+	pixSetter := newPixelSetter(dst)
+
+	procs := 1
+	if parallel {
+		procs = 0
+	}
+
+	tools.Parallelize(procs, dstb.Min.Y, dstb.Max.Y, 1, func(start, end int) {
+		for yi := start; yi < end; yi++ {
+			y := (float32(yi-dstb.Min.Y) + 0.5) / dstHeight
+
+			for xi := dstb.Min.X; xi < dstb.Max.X; xi++ {
+				x := (float32(xi-dstb.Min.X) + 0.5) / dstWidth
+
+				sx, sy := fn(x, y)
+				sx *= srcWidth
+				sy *= srcHeight
+
+				if edge == EdgeTransparent && !(sx >= 0 && sy >= 0 && sx <= srcWidth && sy <= srcHeight) {
+					pixSetter.setPixel(xi, yi, Pixel{0, 0, 0, 0})
+					continue
+				}
+
+				x2 := sx - 0.5 + float32(srcb.Min.X)
+				y2 := sy - 0.5 + float32(srcb.Min.Y)
+
+				rgba := interpolate(pixGetter, x2, y2, interpolation)
+				pixSetter.setPixel(xi, yi, rgba)
+			}
+		}
+	})
+}
+
+type inverseMapFilter struct {
+	fn            func(x, y float32) (sx, sy float32)
+	interpolation Interpolation
+	edge          EdgeMode
+}
+
+func (f *inverseMapFilter) Bounds(src image.Rectangle) image.Rectangle {
+	return src
+}
+
+func (f *inverseMapFilter) Apply(dst draw.Image, src image.Image, parallel bool) {
+	inverseMap(dst, src, f.fn, f.interpolation, f.edge, parallel)
+}
+
+// Distorts an image by taking each pixel of the result from the position in the image
+// returned by the fn function using given interpolation method.
+// The coordinates are relative to the size of the image: (0, 0) is the top-left corner
+// and (1, 1) is the bottom-right corner. The fn function gets the coordinates of pixel centers.
+// The edge parameter specifies how positions outside of the image are handled.
+//
+// The fn function is called concurrently, if the filter is applied in parallel.
+// It can be used to make custom displacement filters.
+func InverseMap(fn func(x, y float32) (sx, sy float32), interpolation Interpolation, edge EdgeMode) Filter {
+	if fn == nil {
+		return nil
+	}
+
+	return &inverseMapFilter{
+		fn:            fn,
+		interpolation: interpolation,
+		edge:          edge,
+	}
+}
+
+// Applies the radial lens distortion model to an image.
+// Each point at the distance r from the center is taken from the distance r * (1 + k1*r^2 + k2*r^4),
+// where r is normalized, so that it is 1 in the corners of the image.
+// Positive coefficients give barrel distortion and negative coefficients give pincushion distortion.
+// Thus, to correct the distortion of a lens, use the coefficients with the opposite sign.
+// The image is sampled using given interpolation method, like in the other distortion filters.
+func LensDistortion(k1, k2 float32, interpolation Interpolation) Filter {
+	if k1 == 0 && k2 == 0 {
+		return nil
+	}
+
+	return InverseMap(func(x, y float32) (sx, sy float32) {
+		u := 2*x - 1
+		v := 2*y - 1
+
+		r2 := (u*u + v*v) / 2
+		k := 1 + k1*r2 + k2*r2*r2
+
+		return (u*k + 1) / 2, (v*k + 1) / 2
+	}, interpolation, EdgeTransparent)
+}
+
+// Twists an image around its center.
+// The angle parameter is the rotation angle at the center in radians,
+// which decreases smoothly to zero at the given radius.
+// The radius is relative to the size of the image, so it is elliptical for non-square images.
+// Radius = 0.5 gives the ellipse inscribed in the image.
+func Swirl(angle, radius float32, interpolation Interpolation) Filter {
+	if angle == 0 || radius <= 0 {
+		return nil
+	}
+
+	return InverseMap(func(x, y float32) (sx, sy float32) {
+		u := x - 0.5
+		v := y - 0.5
+
+		d := gm32.Sqrt(u*u+v*v) / radius
+		if d >= 1 {
+			return x, y
+		}
+
+		t := 1 - d
+		sine, cosine := gm32.Sincos(angle * t * t)
+
+		return cosine*u - sine*v + 0.5, sine*u + cosine*v + 0.5
+	}, interpolation, EdgeClamp)
+}
+
+// Displaces pixels of an image along sine waves, making it look like a reflection in rippled water.
+// Each row is shifted horizontally depending on its position and each column is shifted vertically.
+// The amplitude and wavelength parameters are relative to the size of the image.
+func Ripple(amplitude, wavelength float32, interpolation Interpolation) Filter {
+	if amplitude == 0 || wavelength <= 0 {
+		return nil
+	}
+
+	k := 2 * math.Pi / wavelength
+
+	return InverseMap(func(x, y float32) (sx, sy float32) {
+		return x + amplitude*gm32.Sin(k*y), y + amplitude*gm32.Sin(k*x)
+	}, interpolation, EdgeClamp)
+}
+
+// Wraps an image around its center, converting it from rectangular to polar coordinates.
+// The top edge of the image is moved to the center, the bottom edge becomes the circle
+// inscribed in the result (an ellipse for non-square images), and the horizontal axis becomes the angle,
+// which starts at the right and goes clockwise. The area outside of the circle is transparent.
+func ToPolar(interpolation Interpolation) Filter {
+	return InverseMap(func(x, y float32) (sx, sy float32) {
+		u := x - 0.5
+		v := y - 0.5
+
+		theta := gm32.Atan2(v, u)
+		if theta < 0 {
+			theta += 2 * math.Pi
+		}
+
+		return theta / (2 * math.Pi), gm32.Sqrt(u*u+v*v) * 2
+	}, interpolation, EdgeTransparent)
+}
+
+// Unwraps an image around its center, converting it from polar to rectangular coordinates.
+// It is the inverse of ToPolar: the angle becomes the horizontal axis
+// and the distance from the center becomes the vertical axis.
+func FromPolar(interpolation Interpolation) Filter {
+	return InverseMap(func(x, y float32) (sx, sy float32) {
+		sine, cosine := gm32.Sincos(2 * math.Pi * x)
+		r := y / 2
+
+		return r*cosine + 0.5, r*sine + 0.5
+	}, interpolation, EdgeClamp)
+}
+
+type displaceFilter struct {
+	mapImg        image.Image
+	scaleX        float32
+	scaleY        float32
+	interpolation Interpolation
+}
+
+func (f *displaceFilter) Bounds(src image.Rectangle) image.Rectangle {
+	return src
+}
+
+func (f *displaceFilter) Apply(dst draw.Image, src image.Image, parallel bool) {
+	srcb := src.Bounds()
+	mapb := f.mapImg.Bounds()
+
+	srcWidth := float32(srcb.Dx())
+	srcHeight := float32(srcb.Dy())
+
+	// The maximal displacement relative to the size of the image.
+	scaleX := 2 * f.scaleX / srcWidth
+	scaleY := 2 * f.scaleY / srcHeight
+
+	mapGetter := newPixelGetter(f.mapImg)
+
+	inverseMap(dst, src, func(x, y float32) (sx, sy float32) {
+		mx := mapb.Min.X + int(x*srcWidth)
+		my := mapb.Min.Y + int(y*srcHeight)
+
+		// Transparent and absent map pixels don't displace anything.
+		m := mapGetter.getPixel(mx, my)
+		dx := (m.R - 0.5) * m.A
+		dy := (m.G - 0.5) * m.A
+
+		return x + dx*scaleX, y + dy*scaleY
+	}, f.interpolation, EdgeTransparent, parallel)
+}
+
+// Displaces each pixel of an image by the values of the map image, like the GIMP's displace filter.
+// The red channel of the map specifies the horizontal displacement
+// and the green channel specifies the vertical displacement.
+// The value 0.5 doesn't displace a pixel, 0 and 1 displace it by scaleX or scaleY pixels
+// in the negative and positive direction respectively.
+// The map is aligned with the top-left corner of the image.
+//
+// Like in Rotate, pixels displaced from outside of the image are transparent.
+func Displace(mapImg image.Image, scaleX, scaleY float32, interpolation Interpolation) Filter {
+	if mapImg == nil || (scaleX == 0 && scaleY == 0) {
+		return nil
+	}
+
+	return &displaceFilter{
+		mapImg:        mapImg,
+		scaleX:        scaleX,
+		scaleY:        scaleY,
+		interpolation: interpolation,
+	}
+}
@@ -0,0 +1,378 @@
+package gft
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/infastin/gul/gm32"
+	"github.com/infastin/gul/tools"
+)
+
+// Dithering method.
+type DitherMethod int
+
+const (
+	FloydSteinbergDithering DitherMethod = iota
+	AtkinsonDithering
+	JarvisJudiceNinkeDithering
+	SierraDithering
+	TwoRowSierraDithering
+	SierraLiteDithering
+	// Ordered dithering with Bayer matrices of the given size.
+	// Unlike error diffusion, it can be applied in parallel.
+	Bayer2Dithering
+	Bayer4Dithering
+	Bayer8Dithering
+	Bayer16Dithering
+)
+
+type diffusionWeight struct {
+	dx, dy int
+	weight float32
+}
+
+type diffusionKernel struct {
+	weights []diffusionWeight
+	divisor float32
+}
+
+var diffusionKernels = map[DitherMethod]diffusionKernel{
+	FloydSteinbergDithering: {
+		weights: []diffusionWeight{
+			{1, 0, 7},
+			{-1, 1, 3}, {0, 1, 5}, {1, 1, 1},
+		},
+		divisor: 16,
+	},
+	// Atkinson dithering diffuses only 3/4 of the error.
+	AtkinsonDithering: {
+		weights: []diffusionWeight{
+			{1, 0, 1}, {2, 0, 1},
+			{-1, 1, 1}, {0, 1, 1}, {1, 1, 1},
+			{0, 2, 1},
+		},
+		divisor: 8,
+	},
+	JarvisJudiceNinkeDithering: {
+		weights: []diffusionWeight{
+			{1, 0, 7}, {2, 0, 5},
+			{-2, 1, 3}, {-1, 1, 5}, {0, 1, 7}, {1, 1, 5}, {2, 1, 3},
+			{-2, 2, 1}, {-1, 2, 3}, {0, 2, 5}, {1, 2, 3}, {2, 2, 1},
+		},
+		divisor: 48,
+	},
+	SierraDithering: {
+		weights: []diffusionWeight{
+			{1, 0, 5}, {2, 0, 3},
+			{-2, 1, 2}, {-1, 1, 4}, {0, 1, 5}, {1, 1, 4}, {2, 1, 2},
+			{-1, 2, 2}, {0, 2, 3}, {1, 2, 2},
+		},
+		divisor: 32,
+	},
+	TwoRowSierraDithering: {
+		weights: []diffusionWeight{
+			{1, 0, 4}, {2, 0, 3},
+			{-2, 1, 1}, {-1, 1, 2}, {0, 1, 3}, {1, 1, 2}, {2, 1, 1},
+		},
+		divisor: 16,
+	},
+	SierraLiteDithering: {
+		weights: []diffusionWeight{
+			{1, 0, 2},
+			{-1, 1, 1}, {0, 1, 1},
+		},
+		divisor: 4,
+	},
+}
+
+// Returns the size of the Bayer matrix for ordered dithering methods.
+// Otherwise, returns 0.
+func (m DitherMethod) bayerSize() int {
+	switch m {
+	case Bayer2Dithering:
+		return 2
+	case Bayer4Dithering:
+		return 4
+	case Bayer8Dithering:
+		return 8
+	case Bayer16Dithering:
+		return 16
+	}
+
+	return 0
+}
+
+func (m DitherMethod) valid() bool {
+	_, ok := diffusionKernels[m]
+	return ok || m.bayerSize() != 0
+}
+
+// Returns the Bayer threshold matrix of the given size (power of two)
+// with values in the range (-0.5, 0.5).
+func bayerMatrix(size int) []float32 {
+	m := []int{0}
+	for n := 1; n < size; n *= 2 {
+		next := make([]int, 4*n*n)
+		for y := 0; y < n; y++ {
+			for x := 0; x < n; x++ {
+				v := 4 * m[y*n+x]
+				next[y*2*n+x] = v
+				next[y*2*n+x+n] = v + 2
+				next[(y+n)*2*n+x] = v + 3
+				next[(y+n)*2*n+x+n] = v + 1
+			}
+		}
+		m = next
+	}
+
+	thresholds := make([]float32, len(m))
+	for i, v := range m {
+		thresholds[i] = (float32(v)+0.5)/float32(len(m)) - 0.5
+	}
+
+	return thresholds
+}
+
+// Set of colors, which an image is dithered to.
+// Colors are premultiplied, like in quantization.
+type ditherTarget interface {
+	// Returns the nearest color and its index in the palette (or -1).
+	nearest(c [4]float32) ([4]float32, int)
+
+	// Returns the typical distance between neighboring colors in each channel.
+	spread() float32
+}
+
+type paletteTarget struct {
+	coords [][4]float32
+}
+
+func newPaletteTarget(palette color.Palette) *paletteTarget {
+	t := &paletteTarget{coords: make([][4]float32, len(palette))}
+	for i, c := range palette {
+		t.coords[i] = quantSpace{}.coord(pixelFromColor(c))
+	}
+
+	return t
+}
+
+func (t *paletteTarget) nearest(c [4]float32) ([4]float32, int) {
+	i := nearestCoord(t.coords, c)
+	return t.coords[i], i
+}
+
+// The mean distance in the largest channel between each color and its nearest neighbor.
+func (t *paletteTarget) spread() float32 {
+	if len(t.coords) < 2 {
+		return 0
+	}
+
+	var sum float32
+	for i, c0 := range t.coords {
+		min := float32(-1)
+		for j, c1 := range t.coords {
+			if i == j {
+				continue
+			}
+
+			var d float32
+			for k := 0; k < 3; k++ {
+				d = gm32.Max(d, gm32.Abs(c0[k]-c1[k]))
+			}
+
+			if d > 0 && (min < 0 || d < min) {
+				min = d
+			}
+		}
+
+		if min > 0 {
+			sum += min
+		}
+	}
+
+	return sum / float32(len(t.coords))
+}
+
+// Reduces the color channels to the given number of bits. Alpha is not changed.
+type bitsTarget struct {
+	levels float32
+}
+
+func (t *bitsTarget) nearest(c [4]float32) ([4]float32, int) {
+	a := c[3]
+	if a <= 0 {
+		return [4]float32{0, 0, 0, 0}, -1
+	}
+
+	for i := 0; i < 3; i++ {
+		v := gm32.Clamp(c[i]/a, 0, 1)
+		c[i] = gm32.Round(v*t.levels) / t.levels * a
+	}
+
+	return c, -1
+}
+
+func (t *bitsTarget) spread() float32 {
+	return 1 / t.levels
+}
+
+type ditherFilter struct {
+	target     ditherTarget
+	palette    color.Palette
+	method     DitherMethod
+	serpentine bool
+}
+
+func (f *ditherFilter) Bounds(src image.Rectangle) image.Rectangle {
+	return image.Rect(0, 0, src.Dx(), src.Dy())
+}
+
+func (f *ditherFilter) Apply(dst draw.Image, src image.Image, parallel bool) {
+	paletted, isPaletted := dst.(*image.Paletted)
+	if isPaletted && f.palette != nil {
+		paletted.Palette = f.palette
+	} else {
+		isPaletted = false
+	}
+
+	setter := newPixelSetter(dst)
+	dstb := dst.Bounds()
+
+	set := func(x, y int, c [4]float32, idx int) {
+		x += dstb.Min.X
+		y += dstb.Min.Y
+
+		if isPaletted && idx >= 0 {
+			paletted.SetColorIndex(x, y, uint8(idx))
+		} else {
+			setter.setPixel(x, y, quantSpace{}.pixel(c))
+		}
+	}
+
+	if size := f.method.bayerSize(); size != 0 {
+		f.ordered(src, size, set, parallel)
+	} else {
+		f.diffuse(src, set)
+	}
+}
+
+func (f *ditherFilter) ordered(src image.Image, size int, set func(x, y int, c [4]float32, idx int), parallel bool) {
+	srcb := src.Bounds()
+	thresholds := bayerMatrix(size)
+	spread := f.target.spread()
+
+	pixGetter := newPixelGetter(src)
+
+	procs := 1
+	if parallel {
+		procs = 0
+	}
+
+	tools.Parallelize(procs, srcb.Min.Y, srcb.Max.Y, 1, func(start, end int) {
+		for y := start; y < end; y++ {
+			for x := srcb.Min.X; x < srcb.Max.X; x++ {
+				dx, dy := x-srcb.Min.X, y-srcb.Min.Y
+
+				c := quantSpace{}.coord(pixGetter.getPixel(x, y))
+				t := thresholds[(dy%size)*size+dx%size] * spread * c[3]
+				for i := 0; i < 3; i++ {
+					c[i] += t
+				}
+
+				q, idx := f.target.nearest(c)
+				set(dx, dy, q, idx)
+			}
+		}
+	})
+}
+
+// Error diffusion is sequential, so it ignores the parallel flag.
+func (f *ditherFilter) diffuse(src image.Image, set func(x, y int, c [4]float32, idx int)) {
+	srcb := src.Bounds()
+	width, height := srcb.Dx(), srcb.Dy()
+	kernel := diffusionKernels[f.method]
+
+	pixGetter := newPixelGetter(src)
+
+	coords := make([][4]float32, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			coords[y*width+x] = quantSpace{}.coord(pixGetter.getPixel(srcb.Min.X+x, srcb.Min.Y+y))
+		}
+	}
+
+	for y := 0; y < height; y++ {
+		// Serpentine scanning goes from right to left on odd rows
+		// and mirrors the kernel.
+		reverse := f.serpentine && y%2 == 1
+
+		for i := 0; i < width; i++ {
+			x, dir := i, 1
+			if reverse {
+				x, dir = width-1-i, -1
+			}
+
+			c := coords[y*width+x]
+			for k := range c {
+				c[k] = gm32.Clamp(c[k], 0, 1)
+			}
+			c[0], c[1], c[2] = gm32.Min(c[0], c[3]), gm32.Min(c[1], c[3]), gm32.Min(c[2], c[3])
+
+			q, idx := f.target.nearest(c)
+			set(x, y, q, idx)
+
+			for _, w := range kernel.weights {
+				nx, ny := x+w.dx*dir, y+w.dy
+				if nx < 0 || nx >= width || ny >= height {
+					continue
+				}
+
+				n := &coords[ny*width+nx]
+				for k := range n {
+					n[k] += (c[k] - q[k]) * w.weight / kernel.divisor
+				}
+			}
+		}
+	}
+}
+
+// Dithers an image to the given palette using given dithering method.
+// Serpentine scanning (alternating the direction of rows) reduces artifacts of error diffusion methods.
+// It is ignored by ordered dithering methods.
+//
+// Error diffusion is sequential, so only ordered dithering methods are applied in parallel.
+// If the dst image is *image.Paletted, its palette is replaced with the given palette
+// and pixels are set to palette indices.
+// Returns nil, if the palette is empty or has more than 256 colors (like *image.Paletted),
+// or the method is unknown.
+func Dither(palette color.Palette, method DitherMethod, serpentine bool) Filter {
+	if len(palette) == 0 || len(palette) > maxPaletteSize || !method.valid() {
+		return nil
+	}
+
+	p := make(color.Palette, len(palette))
+	copy(p, palette)
+
+	return &ditherFilter{
+		target:     newPaletteTarget(p),
+		palette:    p,
+		method:     method,
+		serpentine: serpentine,
+	}
+}
+
+// Dithers color channels of an image to the given bit depth using given dithering method.
+// The alpha channel is not changed. See Dither.
+// Returns nil, if bits is not in the range [1, 7] or the method is unknown.
+func DitherBits(bits int, method DitherMethod, serpentine bool) Filter {
+	if bits < 1 || bits > 7 || !method.valid() {
+		return nil
+	}
+
+	return &ditherFilter{
+		target:     &bitsTarget{levels: float32(int(1)<<bits - 1)},
+		method:     method,
+		serpentine: serpentine,
+	}
+}
@@ -0,0 +1,420 @@
+package gft
+
+import (
+	"image"
+	"image/draw"
+	"math"
+
+	"github.com/infastin/gul/giu/gcu"
+	"github.com/infastin/gul/gm32"
+	"github.com/infastin/gul/gmu"
+	"github.com/infastin/gul/tools"
+)
+
+// Returns luminance of each pixel of an image premultiplied by its alpha.
+func luminanceBuffer(src image.Image, parallel bool) []float32 {
+	srcb := src.Bounds()
+	srcWidth := srcb.Dx()
+
+	lum := make([]float32, srcWidth*srcb.Dy())
+	pixGetter := newPixelGetter(src)
+
+	procs := 1
+	if parallel {
+		procs = 0
+	}
+
+	tools.Parallelize(procs, srcb.Min.Y, srcb.Max.Y, 1, func(start, end int) {
+		for y := start; y < end; y++ {
+			i := (y - srcb.Min.Y) * srcWidth
+			for x := srcb.Min.X; x < srcb.Max.X; x, i = x+1, i+1 {
+				pix := pixGetter.getPixel(x, y)
+				lum[i] = gcu.RGBLuminance(pix.R, pix.G, pix.B) * pix.A
+			}
+		}
+	})
+
+	return lum
+}
+
+type gradientFilter struct {
+	kernelX   [9]float32
+	kernelY   [9]float32
+	norm      float32
+	grayscale bool
+}
+
+func (f *gradientFilter) Bounds(src image.Rectangle) image.Rectangle {
+	return src
+}
+
+func (f *gradientFilter) Apply(dst draw.Image, src image.Image, parallel bool) {
+	srcb := src.Bounds()
+	dstb := dst.Bounds()
+
+	pixGetter := newEdgePixelGetter(src, EdgeClamp)
+	pixSetter := newPixelSetter(dst)
+
+	procs := 1
+	if parallel {
+		procs = 0
+	}
+
+	tools.Parallelize(procs, srcb.Min.Y, srcb.Max.Y, 1, func(start, end int) {
+		var neighbors [9]Pixel
+
+		for y := start; y < end; y++ {
+			for x := srcb.Min.X; x < srcb.Max.X; x++ {
+				for i := 0; i < 9; i++ {
+					pix := pixGetter.getPixel(x+i%3-1, y+i/3-1)
+					if f.grayscale {
+						v := gcu.RGBLuminance(pix.R, pix.G, pix.B) * pix.A
+						pix = Pixel{v, v, v, 1}
+					} else {
+						pix = Pixel{pix.R * pix.A, pix.G * pix.A, pix.B * pix.A, 1}
+					}
+					neighbors[i] = pix
+				}
+
+				var rx, gx, bx, ry, gy, by float32
+				for i := 0; i < 9; i++ {
+					wx, wy := f.kernelX[i], f.kernelY[i]
+					rx += neighbors[i].R * wx
+					gx += neighbors[i].G * wx
+					bx += neighbors[i].B * wx
+					ry += neighbors[i].R * wy
+					gy += neighbors[i].G * wy
+					by += neighbors[i].B * wy
+				}
+
+				pix := Pixel{
+					R: gm32.Hypot(rx, ry) / f.norm,
+					G: gm32.Hypot(gx, gy) / f.norm,
+					B: gm32.Hypot(bx, by) / f.norm,
+					A: 1,
+				}
+
+				pixSetter.setPixel(dstb.Min.X+x-srcb.Min.X, dstb.Min.Y+y-srcb.Min.Y, pix.Clamp(0, 1))
+			}
+		}
+	})
+}
+
+// Detects edges in an image using Sobel operator.
+// The result is the gradient magnitude of an image.
+// If grayscale is true, the gradient is calculated for the luminance of an image.
+// Otherwise, it is calculated for each color channel separately.
+func Sobel(grayscale bool) Filter {
+	return &gradientFilter{
+		kernelX: [9]float32{
+			-1, 0, 1,
+			-2, 0, 2,
+			-1, 0, 1,
+		},
+		kernelY: [9]float32{
+			-1, -2, -1,
+			0, 0, 0,
+			1, 2, 1,
+		},
+		norm:      4,
+		grayscale: grayscale,
+	}
+}
+
+// Detects edges in an image using Prewitt operator.
+// The result is the gradient magnitude of an image.
+// If grayscale is true, the gradient is calculated for the luminance of an image.
+// Otherwise, it is calculated for each color channel separately.
+func Prewitt(grayscale bool) Filter {
+	return &gradientFilter{
+		kernelX: [9]float32{
+			-1, 0, 1,
+			-1, 0, 1,
+			-1, 0, 1,
+		},
+		kernelY: [9]float32{
+			-1, -1, -1,
+			0, 0, 0,
+			1, 1, 1,
+		},
+		norm:      3,
+		grayscale: grayscale,
+	}
+}
+
+// Detects edges in an image using Scharr operator.
+// The result is the gradient magnitude of an image.
+// If grayscale is true, the gradient is calculated for the luminance of an image.
+// Otherwise, it is calculated for each color channel separately.
+func Scharr(grayscale bool) Filter {
+	return &gradientFilter{
+		kernelX: [9]float32{
+			-3, 0, 3,
+			-10, 0, 10,
+			-3, 0, 3,
+		},
+		kernelY: [9]float32{
+			-3, -10, -3,
+			0, 0, 0,
+			3, 10, 3,
+		},
+		norm:      16,
+		grayscale: grayscale,
+	}
+}
+
+type laplacianFilter struct {
+	sigma     float32
+	grayscale bool
+}
+
+func (f *laplacianFilter) Bounds(src image.Rectangle) image.Rectangle {
+	return src
+}
+
+func (f *laplacianFilter) Apply(dst draw.Image, src image.Image, parallel bool) {
+	srcb := src.Bounds()
+	dstb := dst.Bounds()
+
+	blurred := gaussianBlurred(src, f.sigma, parallel)
+
+	// Scale-normalized response of a unit step edge has a maximum of 1/sqrt(2*pi*e).
+	scale := f.sigma * f.sigma * float32(math.Sqrt(2*math.Pi*math.E))
+
+	pixGetter := newEdgePixelGetter(blurred, EdgeClamp)
+	pixSetter := newPixelSetter(dst)
+
+	procs := 1
+	if parallel {
+		procs = 0
+	}
+
+	tools.Parallelize(procs, srcb.Min.Y, srcb.Max.Y, 1, func(start, end int) {
+		var neighbors [5]Pixel
+		offsets := [5]image.Point{{0, 0}, {-1, 0}, {1, 0}, {0, -1}, {0, 1}}
+
+		for y := start; y < end; y++ {
+			for x := srcb.Min.X; x < srcb.Max.X; x++ {
+				for i, off := range offsets {
+					pix := pixGetter.getPixel(x+off.X, y+off.Y)
+					if f.grayscale {
+						v := gcu.RGBLuminance(pix.R, pix.G, pix.B) * pix.A
+						pix = Pixel{v, v, v, 1}
+					} else {
+						pix = Pixel{pix.R * pix.A, pix.G * pix.A, pix.B * pix.A, 1}
+					}
+					neighbors[i] = pix
+				}
+
+				var r, g, b float32
+				for i := 1; i < 5; i++ {
+					r += neighbors[i].R
+					g += neighbors[i].G
+					b += neighbors[i].B
+				}
+
+				r -= 4 * neighbors[0].R
+				g -= 4 * neighbors[0].G
+				b -= 4 * neighbors[0].B
+
+				pix := Pixel{
+					R: gm32.Abs(r) * scale,
+					G: gm32.Abs(g) * scale,
+					B: gm32.Abs(b) * scale,
+					A: 1,
+				}
+
+				pixSetter.setPixel(dstb.Min.X+x-srcb.Min.X, dstb.Min.Y+y-srcb.Min.Y, pix.Clamp(0, 1))
+			}
+		}
+	})
+}
+
+// Detects edges in an image using Laplacian of Gaussian.
+// The sigma parameter must be positive and specifies the standard deviation of the Gaussian blur
+// applied before calculating Laplacian.
+// If grayscale is true, Laplacian is calculated for the luminance of an image.
+// Otherwise, it is calculated for each color channel separately.
+func LaplacianOfGaussian(sigma float32, grayscale bool) Filter {
+	if sigma <= 0 {
+		return nil
+	}
+
+	return &laplacianFilter{
+		sigma:     sigma,
+		grayscale: grayscale,
+	}
+}
+
+type cannyFilter struct {
+	sigma     float32
+	low, high float32
+}
+
+func (f *cannyFilter) Bounds(src image.Rectangle) image.Rectangle {
+	return src
+}
+
+func (f *cannyFilter) Apply(dst draw.Image, src image.Image, parallel bool) {
+	srcb := src.Bounds()
+	dstb := dst.Bounds()
+
+	width := srcb.Dx()
+	height := srcb.Dy()
+
+	procs := 1
+	if parallel {
+		procs = 0
+	}
+
+	lum := luminanceBuffer(gaussianBlurred(src, f.sigma, parallel), parallel)
+	at := func(x, y int) float32 {
+		x = gmu.ClampInt(x, 0, width-1)
+		y = gmu.ClampInt(y, 0, height-1)
+		return lum[x+y*width]
+	}
+
+	magnitude := make([]float32, width*height)
+	direction := make([]uint8, width*height)
+
+	tools.Parallelize(procs, 0, height, 1, func(start, end int) {
+		for y := start; y < end; y++ {
+			for x := 0; x < width; x++ {
+				gx := (at(x+1, y-1) + 2*at(x+1, y) + at(x+1, y+1)) -
+					(at(x-1, y-1) + 2*at(x-1, y) + at(x-1, y+1))
+				gy := (at(x-1, y+1) + 2*at(x, y+1) + at(x+1, y+1)) -
+					(at(x-1, y-1) + 2*at(x, y-1) + at(x+1, y-1))
+
+				i := x + y*width
+				magnitude[i] = gm32.Hypot(gx, gy) / 4
+
+				// Quantize the gradient direction to one of the four sectors:
+				// 0 - horizontal, 1 - diagonal (/), 2 - vertical, 3 - diagonal (\).
+				angle := gm32.Atan2(gy, gx)
+				if angle < 0 {
+					angle += math.Pi
+				}
+
+				direction[i] = uint8(int(gm32.Round(angle/(math.Pi/4))) % 4)
+			}
+		}
+	})
+
+	const (
+		noEdge = iota
+		weakEdge
+		strongEdge
+	)
+
+	edges := make([]uint8, width*height)
+	neighborOffsets := [4][2]image.Point{
+		{{1, 0}, {-1, 0}},
+		{{1, 1}, {-1, -1}},
+		{{0, 1}, {0, -1}},
+		{{-1, 1}, {1, -1}},
+	}
+
+	tools.Parallelize(procs, 0, height, 1, func(start, end int) {
+		for y := start; y < end; y++ {
+			for x := 0; x < width; x++ {
+				i := x + y*width
+				mag := magnitude[i]
+				if mag < f.low {
+					continue
+				}
+
+				suppressed := false
+				for _, off := range neighborOffsets[direction[i]] {
+					nx, ny := x+off.X, y+off.Y
+					if nx < 0 || ny < 0 || nx >= width || ny >= height {
+						continue
+					}
+
+					if magnitude[nx+ny*width] > mag {
+						suppressed = true
+						break
+					}
+				}
+
+				switch {
+				case suppressed:
+				case mag >= f.high:
+					edges[i] = strongEdge
+				default:
+					edges[i] = weakEdge
+				}
+			}
+		}
+	})
+
+	// Hysteresis: weak edges connected to strong edges become strong.
+	stack := make([]int, 0)
+	for i, e := range edges {
+		if e == strongEdge {
+			stack = append(stack, i)
+		}
+	}
+
+	for len(stack) != 0 {
+		i := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		x, y := i%width, i/width
+		for ny := y - 1; ny <= y+1; ny++ {
+			for nx := x - 1; nx <= x+1; nx++ {
+				if nx < 0 || ny < 0 || nx >= width || ny >= height {
+					continue
+				}
+
+				j := nx + ny*width
+				if edges[j] == weakEdge {
+					edges[j] = strongEdge
+					stack = append(stack, j)
+				}
+			}
+		}
+	}
+
+	pixSetter := newPixelSetter(dst)
+
+	tools.Parallelize(procs, 0, height, 1, func(start, end int) {
+		for y := start; y < end; y++ {
+			for x := 0; x < width; x++ {
+				var v float32
+				if edges[x+y*width] == strongEdge {
+					v = 1
+				}
+
+				pixSetter.setPixel(dstb.Min.X+x, dstb.Min.Y+y, Pixel{v, v, v, 1})
+			}
+		}
+	})
+}
+
+// Detects edges in an image using Canny algorithm.
+// The result is a black image with white one pixel wide edges.
+//
+// The sigma parameter must be positive and specifies the standard deviation of the Gaussian blur
+// applied to reduce noise.
+// The low and high parameters are hysteresis thresholds of the gradient magnitude
+// and must be in the range [0, 1].
+// The pixels with the magnitude greater than high are edges.
+// The pixels with the magnitude between low and high are edges only if they are connected to other edges.
+func Canny(sigma, low, high float32) Filter {
+	if sigma <= 0 {
+		return nil
+	}
+
+	low = gm32.Clamp(low, 0, 1)
+	high = gm32.Clamp(high, 0, 1)
+
+	if low > high {
+		low, high = high, low
+	}
+
+	return &cannyFilter{
+		sigma: sigma,
+		low:   low,
+		high:  high,
+	}
+}
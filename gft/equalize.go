@@ -0,0 +1,292 @@
+package gft
+
+import (
+	"image"
+	"image/draw"
+	"sync"
+
+	"github.com/infastin/gul/giu/gcu"
+	"github.com/infastin/gul/gm32"
+	"github.com/infastin/gul/gmu"
+	"github.com/infastin/gul/tools"
+)
+
+const equalizeBins = 256
+
+// Returns the values of a pixel to be equalized.
+// If luminanceOnly is true, returns HSL lightness. Otherwise, returns red, green and blue channels.
+func equalizeValues(pix Pixel, luminanceOnly bool) (vals [3]float32, numVals int) {
+	if luminanceOnly {
+		_, _, l := gcu.RGBToHSL(pix.R, pix.G, pix.B)
+		vals[0] = l
+		return vals, 1
+	}
+
+	vals[0], vals[1], vals[2] = pix.R, pix.G, pix.B
+	return vals, 3
+}
+
+// Replaces the values of a pixel, which were returned by equalizeValues.
+func setEqualizedValues(pix Pixel, vals [3]float32, luminanceOnly bool) Pixel {
+	if luminanceOnly {
+		h, s, _ := gcu.RGBToHSL(pix.R, pix.G, pix.B)
+		r, g, b := gcu.HSLToRGB(h, s, gm32.Clamp(vals[0], 0, 1))
+		return Pixel{r, g, b, pix.A}
+	}
+
+	return Pixel{vals[0], vals[1], vals[2], pix.A}
+}
+
+func equalizeBin(v float32) int {
+	return int(gm32.Round(gm32.Clamp(v, 0, 1) * (equalizeBins - 1)))
+}
+
+// Makes a lookup table, which maps values to their normalized cumulative distribution.
+// If all values fall into a single bin, the lookup table maps values to themselves.
+func equalizeLut(hist []float32) []float32 {
+	lut := make([]float32, len(hist))
+
+	var total, cdfMin float32
+	for _, n := range hist {
+		if total == 0 {
+			cdfMin = n
+		}
+		total += n
+	}
+
+	if total-cdfMin <= 0 {
+		q := 1 / float32(len(lut)-1)
+		for i := range lut {
+			lut[i] = float32(i) * q
+		}
+		return lut
+	}
+
+	var sum float32
+	for i, n := range hist {
+		sum += n
+		lut[i] = gm32.Max(0, sum-cdfMin) / (total - cdfMin)
+	}
+
+	return lut
+}
+
+type equalizeFilter struct {
+	luminanceOnly bool
+}
+
+func (f *equalizeFilter) Bounds(src image.Rectangle) image.Rectangle {
+	return src
+}
+
+func (f *equalizeFilter) Apply(dst draw.Image, src image.Image, parallel bool) {
+	srcb := src.Bounds()
+	dstb := dst.Bounds()
+
+	pixGetter := newPixelGetter(src)
+	pixSetter := newPixelSetter(dst)
+
+	procs := 1
+	if parallel {
+		procs = 0
+	}
+
+	var hists [3][]float32
+	for i := range hists {
+		hists[i] = make([]float32, equalizeBins)
+	}
+
+	var mu sync.Mutex
+
+	tools.Parallelize(procs, srcb.Min.Y, srcb.Max.Y, 1, func(start, end int) {
+		var local [3][equalizeBins]float32
+
+		for y := start; y < end; y++ {
+			for x := srcb.Min.X; x < srcb.Max.X; x++ {
+				pix := pixGetter.getPixel(x, y)
+				if pix.A == 0 {
+					continue
+				}
+
+				vals, n := equalizeValues(pix, f.luminanceOnly)
+				for i := 0; i < n; i++ {
+					local[i][equalizeBin(vals[i])]++
+				}
+			}
+		}
+
+		mu.Lock()
+		for i := range hists {
+			for j := range hists[i] {
+				hists[i][j] += local[i][j]
+			}
+		}
+		mu.Unlock()
+	})
+
+	var luts [3][]float32
+	for i := range luts {
+		luts[i] = equalizeLut(hists[i])
+	}
+
+	tools.Parallelize(procs, srcb.Min.Y, srcb.Max.Y, 1, func(start, end int) {
+		for y := start; y < end; y++ {
+			for x := srcb.Min.X; x < srcb.Max.X; x++ {
+				pix := pixGetter.getPixel(x, y)
+
+				vals, n := equalizeValues(pix, f.luminanceOnly)
+				for i := 0; i < n; i++ {
+					vals[i] = luts[i][equalizeBin(vals[i])]
+				}
+
+				pix = setEqualizedValues(pix, vals, f.luminanceOnly)
+				pixSetter.setPixel(dstb.Min.X+x-srcb.Min.X, dstb.Min.Y+y-srcb.Min.Y, pix)
+			}
+		}
+	})
+}
+
+// Spreads the values of an image over the full range, so that their histogram becomes flat.
+// If luminanceOnly is true, only the lightness of an image is equalized, so hues are preserved.
+// Otherwise, each color channel is equalized independently.
+func Equalize(luminanceOnly bool) Filter {
+	return &equalizeFilter{
+		luminanceOnly: luminanceOnly,
+	}
+}
+
+type claheFilter struct {
+	tileSize      int
+	clipLimit     float32
+	luminanceOnly bool
+}
+
+func (f *claheFilter) Bounds(src image.Rectangle) image.Rectangle {
+	return src
+}
+
+// Clips the histogram at the given limit and redistributes the excess evenly among all bins.
+func clipHistogram(hist []float32, limit float32) {
+	var excess float32
+	for i, n := range hist {
+		if n > limit {
+			excess += n - limit
+			hist[i] = limit
+		}
+	}
+
+	inc := excess / float32(len(hist))
+	for i := range hist {
+		hist[i] += inc
+	}
+}
+
+func (f *claheFilter) Apply(dst draw.Image, src image.Image, parallel bool) {
+	srcb := src.Bounds()
+	dstb := dst.Bounds()
+
+	tilesX := gmu.MaxInt(1, (srcb.Dx()+f.tileSize-1)/f.tileSize)
+	tilesY := gmu.MaxInt(1, (srcb.Dy()+f.tileSize-1)/f.tileSize)
+
+	pixGetter := newPixelGetter(src)
+	pixSetter := newPixelSetter(dst)
+
+	procs := 1
+	if parallel {
+		procs = 0
+	}
+
+	// Lookup tables of each tile for each channel.
+	luts := make([][3][]float32, tilesX*tilesY)
+
+	tools.Parallelize(procs, 0, tilesX*tilesY, 1, func(start, end int) {
+		for t := start; t < end; t++ {
+			tx, ty := t%tilesX, t/tilesX
+
+			xmin := srcb.Min.X + tx*f.tileSize
+			ymin := srcb.Min.Y + ty*f.tileSize
+			xmax := gmu.MinInt(xmin+f.tileSize, srcb.Max.X)
+			ymax := gmu.MinInt(ymin+f.tileSize, srcb.Max.Y)
+
+			var hists [3][]float32
+			for i := range hists {
+				hists[i] = make([]float32, equalizeBins)
+			}
+
+			for y := ymin; y < ymax; y++ {
+				for x := xmin; x < xmax; x++ {
+					pix := pixGetter.getPixel(x, y)
+					if pix.A == 0 {
+						continue
+					}
+
+					vals, n := equalizeValues(pix, f.luminanceOnly)
+					for i := 0; i < n; i++ {
+						hists[i][equalizeBin(vals[i])]++
+					}
+				}
+			}
+
+			limit := gm32.Max(1, f.clipLimit*float32((xmax-xmin)*(ymax-ymin))/equalizeBins)
+			for i := range hists {
+				clipHistogram(hists[i], limit)
+				luts[t][i] = equalizeLut(hists[i])
+			}
+		}
+	})
+
+	tileSize := float32(f.tileSize)
+
+	tools.Parallelize(procs, srcb.Min.Y, srcb.Max.Y, 1, func(start, end int) {
+		for y := start; y < end; y++ {
+			// Position relative to the tile centers.
+			fy := float32(y-srcb.Min.Y)/tileSize - 0.5
+			ty0 := gmu.ClampInt(int(gm32.Floor(fy)), 0, tilesY-1)
+			ty1 := gmu.MinInt(ty0+1, tilesY-1)
+			wy := gm32.Clamp(fy-float32(ty0), 0, 1)
+
+			for x := srcb.Min.X; x < srcb.Max.X; x++ {
+				fx := float32(x-srcb.Min.X)/tileSize - 0.5
+				tx0 := gmu.ClampInt(int(gm32.Floor(fx)), 0, tilesX-1)
+				tx1 := gmu.MinInt(tx0+1, tilesX-1)
+				wx := gm32.Clamp(fx-float32(tx0), 0, 1)
+
+				pix := pixGetter.getPixel(x, y)
+				vals, n := equalizeValues(pix, f.luminanceOnly)
+
+				for i := 0; i < n; i++ {
+					bin := equalizeBin(vals[i])
+					v00 := luts[tx0+ty0*tilesX][i][bin]
+					v01 := luts[tx1+ty0*tilesX][i][bin]
+					v10 := luts[tx0+ty1*tilesX][i][bin]
+					v11 := luts[tx1+ty1*tilesX][i][bin]
+					vals[i] = gm32.InterpolateBilinear(v00, v01, v10, v11, wx, wy)
+				}
+
+				pix = setEqualizedValues(pix, vals, f.luminanceOnly)
+				pixSetter.setPixel(dstb.Min.X+x-srcb.Min.X, dstb.Min.Y+y-srcb.Min.Y, pix)
+			}
+		}
+	})
+}
+
+// Contrast Limited Adaptive Histogram Equalization.
+// Equalizes each tile of an image separately and smoothly interpolates between tiles,
+// which enhances local contrast.
+//
+// The tileSize parameter is the size of each tile in pixels and must be positive.
+// The clipLimit parameter limits the contrast enhancement, typical values are in the range [2, 4].
+// Values less than or equal to 1 give (almost) the original image.
+// If luminanceOnly is true, only the lightness of an image is equalized, so hues are preserved.
+// Otherwise, each color channel is equalized independently.
+func CLAHE(tileSize int, clipLimit float32, luminanceOnly bool) Filter {
+	if tileSize <= 0 {
+		return nil
+	}
+
+	return &claheFilter{
+		tileSize:      tileSize,
+		clipLimit:     gm32.Max(0, clipLimit),
+		luminanceOnly: luminanceOnly,
+	}
+}
@@ -0,0 +1,427 @@
+package gft
+
+import (
+	"image"
+	"image/draw"
+	"math"
+
+	"github.com/infastin/gul/giu/gcu"
+	"github.com/infastin/gul/gm32"
+	"github.com/infastin/gul/tools"
+)
+
+// Generator of images, which computes the color of each pixel from its position.
+type Generator interface {
+	// Returns the color at the point (x, y).
+	// The coordinates are relative to the size of an image: (0, 0) is the top-left corner
+	// and (1, 1) is the bottom-right corner.
+	// Must be safe for concurrent use.
+	At(x, y float32) Pixel
+}
+
+// Draws the image made by the generator to the dst image.
+func Generate(dst draw.Image, gen Generator, parallel bool) {
+	dstb := dst.Bounds()
+	width := float32(dstb.Dx())
+	height := float32(dstb.Dy())
+
+	pixSetter := newPixelSetter(dst)
+
+	procs := 1
+	if parallel {
+		procs = 0
+	}
+
+	tools.Parallelize(procs, dstb.Min.Y, dstb.Max.Y, 1, func(start, end int) {
+		for yi := start; yi < end; yi++ {
+			y := (float32(yi-dstb.Min.Y) + 0.5) / height
+
+			for xi := dstb.Min.X; xi < dstb.Max.X; xi++ {
+				x := (float32(xi-dstb.Min.X) + 0.5) / width
+				pixSetter.setPixel(xi, yi, gen.At(x, y).Clamp(0, 1))
+			}
+		}
+	})
+}
+
+// Makes a mask of the given size from the image made by the generator.
+// The mask value is the luminance multiplied by the alpha, like in Masked.
+func GenerateMask(gen Generator, width, height int, parallel bool) *image.Alpha {
+	mask := image.NewAlpha(image.Rect(0, 0, width, height))
+	Generate(mask, &maskGenerator{gen}, parallel)
+	return mask
+}
+
+type maskGenerator struct {
+	gen Generator
+}
+
+func (g *maskGenerator) At(x, y float32) Pixel {
+	pix := g.gen.At(x, y)
+	return Pixel{1, 1, 1, gcu.RGBLuminance(pix.R, pix.G, pix.B) * pix.A}
+}
+
+// Coherent noise function of two variables.
+type Noise interface {
+	// Returns the value of noise at the point (x, y) in the range [0, 1].
+	// Features of noise (e.g. cells of a lattice) have the size of 1.
+	// If period > 0, noise repeats itself every period units in both directions.
+	// Must be safe for concurrent use.
+	Eval(x, y float32, period int) float32
+}
+
+const noiseGradientsNum = 256
+
+// Unit vectors with evenly distributed directions.
+var noiseGradients = func() []gm32.Vec2 {
+	grads := make([]gm32.Vec2, noiseGradientsNum)
+	for i := range grads {
+		sine, cosine := gm32.Sincos(2 * math.Pi * float32(i) / noiseGradientsNum)
+		grads[i] = gm32.Vec2{cosine, sine}
+	}
+
+	return grads
+}()
+
+// Returns a pseudo-random hash of a lattice point.
+func latticeHash(seed uint32, x, y int) uint32 {
+	h := seed ^ uint32(x)*0x27d4eb2d ^ uint32(y)*0x165667b1
+	h ^= h >> 15
+	h *= 0x85ebca6b
+	h ^= h >> 13
+	h *= 0xc2b2ae35
+	h ^= h >> 16
+	return h
+}
+
+// Returns a pseudo-random number in the range [0, 1) from a hash.
+func hashFloat(h uint32) float32 {
+	return float32(h>>8) / (1 << 24)
+}
+
+// Wraps a lattice coordinate, if noise is periodic.
+func wrapLattice(i, period int) int {
+	if period <= 0 {
+		return i
+	}
+
+	i %= period
+	if i < 0 {
+		i += period
+	}
+
+	return i
+}
+
+// Smoothstep function of the fifth order.
+func fade(t float32) float32 {
+	return t * t * t * (t*(6*t-15) + 10)
+}
+
+type perlinNoise struct {
+	seed uint32
+}
+
+// Makes Perlin gradient noise.
+func NewPerlinNoise(seed int64) Noise {
+	return &perlinNoise{seed: uint32(seed) ^ uint32(seed>>32)}
+}
+
+func (n *perlinNoise) gradient(x, y, period int) gm32.Vec2 {
+	h := latticeHash(n.seed, wrapLattice(x, period), wrapLattice(y, period))
+	return noiseGradients[h%noiseGradientsNum]
+}
+
+func (n *perlinNoise) Eval(x, y float32, period int) float32 {
+	fx, fy := gm32.Floor(x), gm32.Floor(y)
+	ix, iy := int(fx), int(fy)
+	p := gm32.Vec2{x - fx, y - fy}
+
+	n00 := n.gradient(ix, iy, period).Dot(p)
+	n10 := n.gradient(ix+1, iy, period).Dot(p.Sub(gm32.Vec2{1, 0}))
+	n01 := n.gradient(ix, iy+1, period).Dot(p.Sub(gm32.Vec2{0, 1}))
+	n11 := n.gradient(ix+1, iy+1, period).Dot(p.Sub(gm32.Vec2{1, 1}))
+
+	u, v := fade(p[0]), fade(p[1])
+	value := gm32.InterpolateLinear(
+		gm32.InterpolateLinear(n00, n10, u),
+		gm32.InterpolateLinear(n01, n11, u),
+		v,
+	)
+
+	// The range of 2D Perlin noise is [-sqrt(2)/2, sqrt(2)/2].
+	return gm32.Clamp(value*math.Sqrt2*0.5+0.5, 0, 1)
+}
+
+type simplexNoise struct {
+	seed uint32
+}
+
+// Makes simplex noise, which has less directional artifacts than Perlin noise.
+// Simplex lattice is not rectangular, so periodic simplex noise
+// is made by blending four shifted copies of noise and has slightly lower contrast.
+func NewSimplexNoise(seed int64) Noise {
+	return &simplexNoise{seed: uint32(seed) ^ uint32(seed>>32)}
+}
+
+// Returns the value of simplex noise in the range [-1, 1].
+func (n *simplexNoise) eval(x, y float32) float32 {
+	const (
+		f2 = 0.36602540378 // (sqrt(3) - 1) / 2
+		g2 = 0.21132486540 // (3 - sqrt(3)) / 6
+	)
+
+	// Skews the input space to find the simplex cell.
+	s := (x + y) * f2
+	i := gm32.Floor(x + s)
+	j := gm32.Floor(y + s)
+
+	t := (i + j) * g2
+	p0 := gm32.Vec2{x - (i - t), y - (j - t)}
+
+	var i1, j1 float32
+	if p0[0] > p0[1] {
+		i1, j1 = 1, 0
+	} else {
+		i1, j1 = 0, 1
+	}
+
+	p1 := gm32.Vec2{p0[0] - i1 + g2, p0[1] - j1 + g2}
+	p2 := gm32.Vec2{p0[0] - 1 + 2*g2, p0[1] - 1 + 2*g2}
+
+	ii, jj := int(i), int(j)
+	corners := [3]struct {
+		p      gm32.Vec2
+		di, dj int
+	}{
+		{p0, 0, 0},
+		{p1, int(i1), int(j1)},
+		{p2, 1, 1},
+	}
+
+	var value float32
+	for _, c := range corners {
+		t := 0.5 - c.p.Dot(c.p)
+		if t <= 0 {
+			continue
+		}
+
+		grad := noiseGradients[latticeHash(n.seed, ii+c.di, jj+c.dj)%noiseGradientsNum]
+		t *= t
+		value += t * t * grad.Dot(c.p)
+	}
+
+	// Scales the value approximately to the range [-1, 1].
+	return gm32.Clamp(99.2*value, -1, 1)
+}
+
+func (n *simplexNoise) Eval(x, y float32, period int) float32 {
+	if period <= 0 {
+		return n.eval(x, y)*0.5 + 0.5
+	}
+
+	p := float32(period)
+	x = gm32.Mod(x, p)
+	if x < 0 {
+		x += p
+	}
+	y = gm32.Mod(y, p)
+	if y < 0 {
+		y += p
+	}
+
+	u, v := x/p, y/p
+	w00, w10 := (1-u)*(1-v), u*(1-v)
+	w01, w11 := (1-u)*v, u*v
+
+	value := w00*n.eval(x, y) + w10*n.eval(x-p, y) +
+		w01*n.eval(x, y-p) + w11*n.eval(x-p, y-p)
+
+	// Compensates the loss of contrast caused by blending.
+	value /= gm32.Sqrt(w00*w00 + w10*w10 + w01*w01 + w11*w11)
+
+	return gm32.Clamp(value*0.5+0.5, 0, 1)
+}
+
+type valueNoise struct {
+	seed uint32
+}
+
+// Makes value noise, which smoothly interpolates random values at the points of a lattice.
+func NewValueNoise(seed int64) Noise {
+	return &valueNoise{seed: uint32(seed) ^ uint32(seed>>32)}
+}
+
+func (n *valueNoise) value(x, y, period int) float32 {
+	return hashFloat(latticeHash(n.seed, wrapLattice(x, period), wrapLattice(y, period)))
+}
+
+func (n *valueNoise) Eval(x, y float32, period int) float32 {
+	fx, fy := gm32.Floor(x), gm32.Floor(y)
+	ix, iy := int(fx), int(fy)
+
+	u, v := fade(x-fx), fade(y-fy)
+
+	return gm32.InterpolateLinear(
+		gm32.InterpolateLinear(n.value(ix, iy, period), n.value(ix+1, iy, period), u),
+		gm32.InterpolateLinear(n.value(ix, iy+1, period), n.value(ix+1, iy+1, period), u),
+		v,
+	)
+}
+
+type worleyNoise struct {
+	seed uint32
+}
+
+// Makes Worley (cellular) noise.
+// Each cell of a lattice has a random feature point and the value of noise
+// is the distance to the nearest feature point (clamped to 1).
+func NewWorleyNoise(seed int64) Noise {
+	return &worleyNoise{seed: uint32(seed) ^ uint32(seed>>32)}
+}
+
+func (n *worleyNoise) Eval(x, y float32, period int) float32 {
+	fx, fy := gm32.Floor(x), gm32.Floor(y)
+	ix, iy := int(fx), int(fy)
+	p := gm32.Vec2{x, y}
+
+	minDist := float32(math.MaxFloat32)
+
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			cx, cy := ix+dx, iy+dy
+
+			h := latticeHash(n.seed, wrapLattice(cx, period), wrapLattice(cy, period))
+			feature := gm32.Vec2{
+				float32(cx) + hashFloat(h),
+				float32(cy) + hashFloat(latticeHash(h, 1, 0)),
+			}
+
+			minDist = gm32.Min(minDist, feature.Sub(p).Len())
+		}
+	}
+
+	return gm32.Min(minDist, 1)
+}
+
+type fbmNoise struct {
+	noise      Noise
+	octaves    int
+	lacunarity float32
+	gain       float32
+}
+
+// Makes fractal Brownian motion, which sums the given number of octaves of noise.
+// Each octave has the frequency multiplied by lacunarity and the amplitude multiplied by gain.
+// Usually lacunarity = 2 and gain = 0.5.
+//
+// Periodic noise stays periodic only if lacunarity is an integer.
+// Returns nil, if the noise is nil or octaves <= 0.
+func FBM(noise Noise, octaves int, lacunarity, gain float32) Noise {
+	if noise == nil || octaves <= 0 {
+		return nil
+	}
+
+	return &fbmNoise{
+		noise:      noise,
+		octaves:    octaves,
+		lacunarity: lacunarity,
+		gain:       gain,
+	}
+}
+
+func (n *fbmNoise) Eval(x, y float32, period int) float32 {
+	var sum, total float32
+	amplitude, frequency := float32(1), float32(1)
+
+	for i := 0; i < n.octaves; i++ {
+		p := 0
+		if period > 0 {
+			p = int(gm32.Round(float32(period) * frequency))
+		}
+
+		// Octaves are shifted by whole units, so that they are not correlated.
+		offset := float32(i * 131)
+		value := n.noise.Eval(x*frequency+offset, y*frequency+offset, p)
+
+		sum += (2*value - 1) * amplitude
+		total += amplitude
+
+		amplitude *= n.gain
+		frequency *= n.lacunarity
+	}
+
+	if total == 0 {
+		return 0.5
+	}
+
+	return gm32.Clamp(sum/total*0.5+0.5, 0, 1)
+}
+
+type noiseGenerator struct {
+	noise     Noise
+	frequency float32
+	period    int
+}
+
+func newNoiseGenerator(noise Noise, frequency float32, tileable bool) noiseGenerator {
+	g := noiseGenerator{
+		noise:     noise,
+		frequency: frequency,
+	}
+
+	if tileable {
+		g.frequency = gm32.Max(1, gm32.Round(frequency))
+		g.period = int(g.frequency)
+	}
+
+	return g
+}
+
+func (g noiseGenerator) eval(x, y float32) float32 {
+	return g.noise.Eval(x*g.frequency, y*g.frequency, g.period)
+}
+
+func (g noiseGenerator) At(x, y float32) Pixel {
+	v := g.eval(x, y)
+	return Pixel{v, v, v, 1}
+}
+
+// Makes a generator of grayscale images from noise.
+// The frequency is the number of features (e.g. cells of a lattice) along each side of an image.
+// If the image must be tileable, the frequency is rounded to an integer.
+//
+// The result can be used as a mask (e.g. in Masked) or as a layer in Blend.
+// Returns nil, if the noise is nil or frequency <= 0.
+func NoiseGenerator(noise Noise, frequency float32, tileable bool) Generator {
+	if noise == nil || frequency <= 0 {
+		return nil
+	}
+
+	g := newNoiseGenerator(noise, frequency, tileable)
+	return &g
+}
+
+type displacementGenerator struct {
+	x noiseGenerator
+	y noiseGenerator
+}
+
+func (g *displacementGenerator) At(x, y float32) Pixel {
+	return Pixel{g.x.eval(x, y), g.y.eval(x, y), 0.5, 1}
+}
+
+// Makes a generator of displacement maps for Displace,
+// where the red channel is taken from the nx noise and the green channel is taken from the ny noise.
+// See NoiseGenerator.
+// Returns nil, if any noise is nil or frequency <= 0.
+func DisplacementGenerator(nx, ny Noise, frequency float32, tileable bool) Generator {
+	if nx == nil || ny == nil || frequency <= 0 {
+		return nil
+	}
+
+	return &displacementGenerator{
+		x: newNoiseGenerator(nx, frequency, tileable),
+		y: newNoiseGenerator(ny, frequency, tileable),
+	}
+}
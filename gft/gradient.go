@@ -0,0 +1,242 @@
+package gft
+
+import (
+	"image/color"
+	"math"
+	"sort"
+
+	"github.com/infastin/gul/giu/gcu"
+	"github.com/infastin/gul/gm32"
+)
+
+// Color space, where colors of a gradient are interpolated.
+type GradientSpace int
+
+const (
+	// Interpolates sRGB values, like most image editors do.
+	RGBGradientSpace GradientSpace = iota
+	// Interpolates linear RGB values, which gives physically correct mixing of light.
+	LinearRGBGradientSpace
+	// Interpolates HSL values, going around the hue circle by the shortest path.
+	// Unlike other spaces, it uses straight alpha, so colors may bleed toward transparent stops.
+	HSLGradientSpace
+	// Interpolates in the perceptual Oklab color space, which gives the most even transitions.
+	PerceptualGradientSpace
+)
+
+// Color of a gradient at the given offset in the range [0, 1].
+type GradientStop struct {
+	Offset float32
+	Color  color.Color
+}
+
+// Gradient color ramp, which interpolates colors between stops.
+type colorRamp struct {
+	offsets []float32
+	// Colors of stops in the gradient space.
+	// All spaces except HSL use premultiplied alpha.
+	coords [][4]float32
+	space  GradientSpace
+}
+
+// Returns nil, if there are no stops.
+func newColorRamp(stops []GradientStop, space GradientSpace) *colorRamp {
+	if len(stops) == 0 {
+		return nil
+	}
+
+	sorted := make([]GradientStop, len(stops))
+	copy(sorted, stops)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Offset < sorted[j].Offset
+	})
+
+	ramp := &colorRamp{
+		offsets: make([]float32, len(sorted)),
+		coords:  make([][4]float32, len(sorted)),
+		space:   space,
+	}
+
+	for i, stop := range sorted {
+		ramp.offsets[i] = gm32.Clamp(stop.Offset, 0, 1)
+		ramp.coords[i] = ramp.toSpace(stop.Color)
+	}
+
+	return ramp
+}
+
+func (r *colorRamp) toSpace(c color.Color) [4]float32 {
+	if r.space == HSLGradientSpace {
+		hsla := gcu.HSLAModel.Convert(c).(gcu.HSLA)
+		return [4]float32{hsla.H, hsla.S, hsla.L, hsla.A}
+	}
+
+	pix := pixelFromColor(c)
+	c0, c1, c2 := pix.R, pix.G, pix.B
+
+	switch r.space {
+	case LinearRGBGradientSpace:
+		c0, c1, c2 = gcu.SRGBToLinear(c0), gcu.SRGBToLinear(c1), gcu.SRGBToLinear(c2)
+	case PerceptualGradientSpace:
+		c0, c1, c2 = gcu.RGBToOklab(c0, c1, c2)
+	}
+
+	return [4]float32{c0 * pix.A, c1 * pix.A, c2 * pix.A, pix.A}
+}
+
+func (r *colorRamp) fromSpace(c [4]float32) Pixel {
+	if r.space == HSLGradientSpace {
+		red, green, blue := gcu.HSLToRGB(c[0], c[1], c[2])
+		return Pixel{red, green, blue, c[3]}.Clamp(0, 1)
+	}
+
+	a := c[3]
+	if a <= 0 {
+		return Pixel{0, 0, 0, 0}
+	}
+
+	c0, c1, c2 := c[0]/a, c[1]/a, c[2]/a
+
+	switch r.space {
+	case LinearRGBGradientSpace:
+		c0, c1, c2 = gcu.LinearToSRGB(c0), gcu.LinearToSRGB(c1), gcu.LinearToSRGB(c2)
+	case PerceptualGradientSpace:
+		c0, c1, c2 = gcu.OklabToRGB(c0, c1, c2)
+	}
+
+	return Pixel{c0, c1, c2, a}.Clamp(0, 1)
+}
+
+func (r *colorRamp) mix(c0, c1 [4]float32, t float32) [4]float32 {
+	var c [4]float32
+	for i := range c {
+		c[i] = gm32.InterpolateLinear(c0[i], c1[i], t)
+	}
+
+	if r.space == HSLGradientSpace {
+		h0, h1 := c0[0], c1[0]
+
+		// Hue of a gray color is undefined.
+		switch {
+		case c0[1] == 0:
+			h0 = h1
+		case c1[1] == 0:
+			h1 = h0
+		}
+
+		d := h1 - h0
+		switch {
+		case d > 0.5:
+			d--
+		case d < -0.5:
+			d++
+		}
+
+		c[0] = gm32.Mod(h0+d*t+1, 1)
+	}
+
+	return c
+}
+
+// Returns the color at the given offset.
+// Offsets outside of the range of stops get the color of the nearest stop.
+func (r *colorRamp) at(t float32) Pixel {
+	n := len(r.offsets)
+
+	switch {
+	case t <= r.offsets[0]:
+		return r.fromSpace(r.coords[0])
+	case t >= r.offsets[n-1]:
+		return r.fromSpace(r.coords[n-1])
+	}
+
+	i := sort.Search(n, func(i int) bool {
+		return r.offsets[i] > t
+	})
+
+	t0, t1 := r.offsets[i-1], r.offsets[i]
+	u := (t - t0) / (t1 - t0)
+
+	return r.fromSpace(r.mix(r.coords[i-1], r.coords[i], u))
+}
+
+type gradientShape func(p gm32.Vec2) float32
+
+type gradientGenerator struct {
+	shape gradientShape
+	ramp  *colorRamp
+}
+
+func (g *gradientGenerator) At(x, y float32) Pixel {
+	return g.ramp.at(g.shape(gm32.Vec2{x, y}))
+}
+
+func newGradient(shape gradientShape, stops []GradientStop, space GradientSpace) Generator {
+	ramp := newColorRamp(stops, space)
+	if ramp == nil {
+		return nil
+	}
+
+	return &gradientGenerator{
+		shape: shape,
+		ramp:  ramp,
+	}
+}
+
+// Makes a generator of a gradient, which changes color along the line from the start point to the end point.
+// All coordinates of gradients are relative to the size of an image.
+// Use Generate to fill an image with a gradient and GenerateMask to make a mask.
+// Returns nil, if there are no stops or the start point is equal to the end point.
+func LinearGradient(start, end gm32.Vec2, stops []GradientStop, space GradientSpace) Generator {
+	dir := end.Sub(start)
+	lenSq := dir.Dot(dir)
+	if lenSq == 0 {
+		return nil
+	}
+
+	return newGradient(func(p gm32.Vec2) float32 {
+		return p.Sub(start).Dot(dir) / lenSq
+	}, stops, space)
+}
+
+// Makes a generator of a gradient, which changes color with the distance from the center.
+// The radius is relative to the size of an image, so the gradient is elliptical for non-square images.
+// See LinearGradient.
+func RadialGradient(center gm32.Vec2, radius float32, stops []GradientStop, space GradientSpace) Generator {
+	return newGradient(func(p gm32.Vec2) float32 {
+		if radius <= 0 {
+			return 1
+		}
+
+		return p.Sub(center).Len() / radius
+	}, stops, space)
+}
+
+// Makes a generator of a gradient, which changes color with the angle around the center.
+// The angle is the starting direction of the gradient in radians, which goes clockwise.
+// See LinearGradient.
+func ConicGradient(center gm32.Vec2, angle float32, stops []GradientStop, space GradientSpace) Generator {
+	return newGradient(func(p gm32.Vec2) float32 {
+		d := p.Sub(center)
+		t := gm32.Mod((gm32.Atan2(d[1], d[0])-angle)/(2*math.Pi), 1)
+		if t < 0 {
+			t++
+		}
+
+		return t
+	}, stops, space)
+}
+
+// Makes a generator of a gradient, which changes color with the Manhattan distance from the center,
+// so that it has the shape of a diamond.
+// See RadialGradient.
+func DiamondGradient(center gm32.Vec2, radius float32, stops []GradientStop, space GradientSpace) Generator {
+	return newGradient(func(p gm32.Vec2) float32 {
+		if radius <= 0 {
+			return 1
+		}
+
+		d := p.Sub(center)
+		return (gm32.Abs(d[0]) + gm32.Abs(d[1])) / radius
+	}, stops, space)
+}
@@ -0,0 +1,188 @@
+package gft
+
+import (
+	"image"
+	"math"
+	"sync"
+
+	"github.com/infastin/gul/giu/gcu"
+	"github.com/infastin/gul/gm32"
+	"github.com/infastin/gul/tools"
+)
+
+// Histogram and statistics of a single image channel.
+type ChannelHistogram struct {
+	// Number of values in each bin.
+	// The bin i contains values closest to i / (len(Bins) - 1).
+	Bins []uint64
+
+	// Total number of values.
+	Total uint64
+
+	sum, sumSq float64
+	min, max   float32
+}
+
+func makeChannelHistogram(bins int) ChannelHistogram {
+	return ChannelHistogram{
+		Bins: make([]uint64, bins),
+		min:  1,
+		max:  0,
+	}
+}
+
+func (h *ChannelHistogram) addValue(v float32) {
+	v = gm32.Clamp(v, 0, 1)
+
+	h.Bins[int(gm32.Round(v*float32(len(h.Bins)-1)))]++
+	h.Total++
+
+	h.sum += float64(v)
+	h.sumSq += float64(v) * float64(v)
+
+	h.min = gm32.Min(h.min, v)
+	h.max = gm32.Max(h.max, v)
+}
+
+func (h *ChannelHistogram) add(other *ChannelHistogram) {
+	for i := range h.Bins {
+		h.Bins[i] += other.Bins[i]
+	}
+
+	h.Total += other.Total
+	h.sum += other.sum
+	h.sumSq += other.sumSq
+	h.min = gm32.Min(h.min, other.min)
+	h.max = gm32.Max(h.max, other.max)
+}
+
+// Returns the mean of the values.
+func (h *ChannelHistogram) Mean() float32 {
+	if h.Total == 0 {
+		return 0
+	}
+
+	return float32(h.sum / float64(h.Total))
+}
+
+// Returns the standard deviation of the values.
+func (h *ChannelHistogram) StdDev() float32 {
+	if h.Total == 0 {
+		return 0
+	}
+
+	mean := h.sum / float64(h.Total)
+	variance := h.sumSq/float64(h.Total) - mean*mean
+
+	return float32(math.Sqrt(math.Max(0, variance)))
+}
+
+// Returns the minimal value.
+func (h *ChannelHistogram) Min() float32 {
+	if h.Total == 0 {
+		return 0
+	}
+
+	return h.min
+}
+
+// Returns the maximal value.
+func (h *ChannelHistogram) Max() float32 {
+	if h.Total == 0 {
+		return 0
+	}
+
+	return h.max
+}
+
+// Returns the value below which the given percentage of values falls.
+// The percentage parameter must be in the range [0, 100].
+// The precision of the result depends on the number of bins.
+func (h *ChannelHistogram) Percentile(perc float32) float32 {
+	if h.Total == 0 {
+		return 0
+	}
+
+	perc = gm32.Clamp(perc, 0, 100)
+	target := uint64(gm32.Round(float32(h.Total-1) * perc / 100))
+	q := 1 / float32(len(h.Bins)-1)
+
+	var sum uint64
+	for i, n := range h.Bins {
+		sum += n
+		if sum > target {
+			return float32(i) * q
+		}
+	}
+
+	return 1
+}
+
+// Histograms and statistics of an image.
+// Fully transparent pixels are counted only in the alpha histogram.
+type ImageHistogram struct {
+	Red       ChannelHistogram
+	Green     ChannelHistogram
+	Blue      ChannelHistogram
+	Alpha     ChannelHistogram
+	Luminance ChannelHistogram
+}
+
+func newImageHistogram(bins int) *ImageHistogram {
+	return &ImageHistogram{
+		Red:       makeChannelHistogram(bins),
+		Green:     makeChannelHistogram(bins),
+		Blue:      makeChannelHistogram(bins),
+		Alpha:     makeChannelHistogram(bins),
+		Luminance: makeChannelHistogram(bins),
+	}
+}
+
+func (h *ImageHistogram) add(other *ImageHistogram) {
+	h.Red.add(&other.Red)
+	h.Green.add(&other.Green)
+	h.Blue.add(&other.Blue)
+	h.Alpha.add(&other.Alpha)
+	h.Luminance.add(&other.Luminance)
+}
+
+// Calculates histograms of each channel and luminance of an image.
+// The bins parameter is the number of bins in each histogram and must be at least 2.
+// Use 256 bins to get exact statistics of 8-bit images.
+func Histogram(img image.Image, bins int) *ImageHistogram {
+	if bins < 2 {
+		bins = 2
+	}
+
+	srcb := img.Bounds()
+	pixGetter := newPixelGetter(img)
+
+	hist := newImageHistogram(bins)
+	var mu sync.Mutex
+
+	tools.Parallelize(0, srcb.Min.Y, srcb.Max.Y, 1, func(start, end int) {
+		local := newImageHistogram(bins)
+
+		for y := start; y < end; y++ {
+			for x := srcb.Min.X; x < srcb.Max.X; x++ {
+				pix := pixGetter.getPixel(x, y)
+
+				local.Alpha.addValue(pix.A)
+				if pix.A == 0 {
+					continue
+				}
+
+				local.Red.addValue(pix.R)
+				local.Green.addValue(pix.G)
+				local.Blue.addValue(pix.B)
+				local.Luminance.addValue(gcu.RGBLuminance(pix.R, pix.G, pix.B))
+			}
+		}
+
+		mu.Lock()
+		hist.add(local)
+		mu.Unlock()
+	})
+
+	return hist
+}
@@ -0,0 +1,141 @@
+package gft
+
+import (
+	"image"
+
+	"github.com/infastin/gul/gm32"
+)
+
+type levelsParams struct {
+	inBlack, inWhite   float32
+	gamma              float32
+	outBlack, outWhite float32
+}
+
+func (p levelsParams) fn(x float32) float32 {
+	v := (x - p.inBlack) / (p.inWhite - p.inBlack)
+	v = gm32.Clamp(v, 0, 1)
+
+	if p.gamma != 1 {
+		v = gm32.Pow(v, 1/p.gamma)
+	}
+
+	return p.outBlack + v*(p.outWhite-p.outBlack)
+}
+
+// The inWhite point must not be less than the inBlack point.
+// If they are equal (e.g. a channel of an image has a single value),
+// the input range is widened to the smallest possible range, which acts as a threshold.
+func makeLevelsParams(inBlack, inWhite, gamma, outBlack, outWhite float32) levelsParams {
+	inBlack = gm32.Clamp(inBlack, 0, 1)
+	inWhite = gm32.Clamp(inWhite, 0, 1)
+
+	if inWhite-inBlack < 1.0e-5 {
+		inWhite = gm32.Min(1, inBlack+1.0e-5)
+		inBlack = inWhite - 1.0e-5
+	}
+
+	return levelsParams{
+		inBlack:  inBlack,
+		inWhite:  inWhite,
+		gamma:    gm32.Max(1.0e-5, gamma),
+		outBlack: gm32.Clamp(outBlack, 0, 1),
+		outWhite: gm32.Clamp(outWhite, 0, 1),
+	}
+}
+
+type levelsFilter struct {
+	chans [3]levelsParams
+}
+
+func (f *levelsFilter) Fn(x float32) float32 {
+	return f.chans[0].fn(x)
+}
+
+func (f *levelsFilter) ChannelFn(x float32, ch Channel) float32 {
+	if ch == AlphaChannel {
+		return x
+	}
+
+	return f.chans[ch].fn(x)
+}
+
+func (f *levelsFilter) UseLut() bool {
+	return true
+}
+
+// Adjusts tones of an image by remapping the input range [inBlack, inWhite]
+// to the output range [outBlack, outWhite] with the midtones corrected by gamma.
+// The black and white points must be in the range [0, 1].
+// The gamma parameter must be positive. Gamma = 1 leaves midtones unchanged,
+// gamma less than 1 darkens them and gamma greater than 1 lightens them.
+// To invert an image, swap the output points instead of the input points.
+// Returns nil, if inBlack >= inWhite.
+func Levels(inBlack, inWhite, gamma, outBlack, outWhite float32) ChannelwiseColorchanFilter {
+	if inBlack == 0 && inWhite == 1 && gamma == 1 && outBlack == 0 && outWhite == 1 {
+		return nil
+	}
+
+	if gm32.Clamp(inBlack, 0, 1) >= gm32.Clamp(inWhite, 0, 1) {
+		return nil
+	}
+
+	p := makeLevelsParams(inBlack, inWhite, gamma, outBlack, outWhite)
+	return &levelsFilter{
+		chans: [3]levelsParams{p, p, p},
+	}
+}
+
+// Stretches each color channel of an image so that its values cover the full range.
+// The input black and white points are calculated from the histogram of the img image.
+// The lowClip and highClip parameters are the percentages of the darkest and the brightest
+// values to be clipped and must be in the range [0, 100].
+//
+// Since channels are stretched independently, color casts are removed too.
+func AutoLevels(img image.Image, lowClip, highClip float32) ChannelwiseColorchanFilter {
+	hist := Histogram(img, 256)
+	if hist.Luminance.Total == 0 {
+		return nil
+	}
+
+	lowClip = gm32.Clamp(lowClip, 0, 100)
+	highClip = gm32.Clamp(highClip, 0, 100-lowClip)
+
+	f := &levelsFilter{}
+	for ch, h := range []*ChannelHistogram{&hist.Red, &hist.Green, &hist.Blue} {
+		black := h.Percentile(lowClip)
+		white := h.Percentile(100 - highClip)
+		f.chans[ch] = makeLevelsParams(black, white, 1, 0, 1)
+	}
+
+	return f
+}
+
+// Stretches all color channels of an image equally so that its values cover the full range.
+// The input black and white points are calculated from the histogram of the img image.
+// The lowClip and highClip parameters are the percentages of the darkest and the brightest
+// values to be clipped and must be in the range [0, 100].
+//
+// Unlike AutoLevels, it preserves hues of an image.
+func AutoContrast(img image.Image, lowClip, highClip float32) ChannelwiseColorchanFilter {
+	hist := Histogram(img, 256)
+	if hist.Luminance.Total == 0 {
+		return nil
+	}
+
+	colors := makeChannelHistogram(256)
+	colors.add(&hist.Red)
+	colors.add(&hist.Green)
+	colors.add(&hist.Blue)
+
+	lowClip = gm32.Clamp(lowClip, 0, 100)
+	highClip = gm32.Clamp(highClip, 0, 100-lowClip)
+
+	black := colors.Percentile(lowClip)
+	white := colors.Percentile(100 - highClip)
+
+	p := makeLevelsParams(black, white, 1, 0, 1)
+	return &levelsFilter{
+		chans: [3]levelsParams{p, p, p},
+	}
+}
@@ -0,0 +1,262 @@
+package gft
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"github.com/infastin/gul/gm32"
+	"github.com/srwiley/rasterx"
+	"golang.org/x/image/math/fixed"
+)
+
+// Adds a shape of an image with the size (w, h) to the adder.
+type maskShape func(w, h float64, adder rasterx.Adder)
+
+// Builds masks from vector shapes.
+// All coordinates and sizes are relative to the size of a mask, like in crop filters,
+// so the same builder can be used to make masks of any size.
+// The resulting mask is the union of all shapes.
+type MaskBuilder struct {
+	shapes  []maskShape
+	feather float32
+}
+
+func NewMaskBuilder() *MaskBuilder {
+	return &MaskBuilder{}
+}
+
+// Sets the feathering of shape edges.
+// Edges are blurred with the Gaussian blur with the given sigma in pixels.
+// Zero sigma disables feathering.
+func (b *MaskBuilder) Feather(sigma float32) *MaskBuilder {
+	b.feather = gm32.Max(0, sigma)
+	return b
+}
+
+// Adds a rectangle starting at a given position (x, y) with a given size (width, height).
+func (b *MaskBuilder) Rectangle(x, y, width, height float32) *MaskBuilder {
+	b.shapes = append(b.shapes, func(w, h float64, adder rasterx.Adder) {
+		minX, minY := float64(x)*w, float64(y)*h
+		maxX, maxY := float64(x+width)*w, float64(y+height)*h
+		rasterx.AddRect(minX, minY, maxX, maxY, 0, adder)
+	})
+
+	return b
+}
+
+// Adds a rectangle starting at a given position (x, y) with a given size (width, height)
+// and with rounded corners of the given radius.
+// The radius is relative to the smaller side of a mask, so corners are always circular.
+func (b *MaskBuilder) RoundedRectangle(x, y, width, height, radius float32) *MaskBuilder {
+	b.shapes = append(b.shapes, func(w, h float64, adder rasterx.Adder) {
+		minX, minY := float64(x)*w, float64(y)*h
+		maxX, maxY := float64(x+width)*w, float64(y+height)*h
+		r := float64(radius) * math.Min(w, h)
+		rasterx.AddRoundRect(minX, minY, maxX, maxY, r, r, 0, rasterx.RoundGap, adder)
+	})
+
+	return b
+}
+
+// Adds an ellipse of a radii (rx, ry) with the center at a given position (cx, cy).
+// The rx radius is relative to the width of a mask and the ry radius is relative to the height of a mask.
+func (b *MaskBuilder) Ellipse(cx, cy, rx, ry float32) *MaskBuilder {
+	b.shapes = append(b.shapes, func(w, h float64, adder rasterx.Adder) {
+		rasterx.AddEllipse(float64(cx)*w, float64(cy)*h, float64(rx)*w, float64(ry)*h, 0, adder)
+	})
+
+	return b
+}
+
+// Adds a polygon with the given vertices.
+// The polygon must have at least 3 vertices, otherwise it is ignored.
+func (b *MaskBuilder) Polygon(points []gm32.Vec2) *MaskBuilder {
+	if len(points) < 3 {
+		return b
+	}
+
+	pts := make([]gm32.Vec2, len(points))
+	copy(pts, points)
+
+	b.shapes = append(b.shapes, func(w, h float64, adder rasterx.Adder) {
+		polygonPath(pts).addTo(w, h, adder)
+	})
+
+	return b
+}
+
+// Adds a path. Each subpath is implicitly closed.
+// The path is copied, so it can be changed later without affecting the builder.
+func (b *MaskBuilder) Path(path *Path) *MaskBuilder {
+	if path == nil || len(path.cmds) == 0 {
+		return b
+	}
+
+	p := &Path{cmds: make([]pathCmd, len(path.cmds))}
+	copy(p.cmds, path.cmds)
+
+	b.shapes = append(b.shapes, func(w, h float64, adder rasterx.Adder) {
+		p.addTo(w, h, adder)
+	})
+
+	return b
+}
+
+// Rasterizes the shapes of a mask with the size (width, height) to the dst image.
+// The offset is the position of the top-left corner of the dst image in the mask.
+// The dst image must be cleared.
+func (b *MaskBuilder) rasterize(dst *image.Alpha, width, height int, offset image.Point) {
+	dstb := dst.Bounds()
+	if len(b.shapes) == 0 || dstb.Empty() {
+		return
+	}
+
+	w, h := dstb.Dx(), dstb.Dy()
+
+	scanner := rasterx.NewScannerGV(w, h, dst, dstb)
+	scanner.SetColor(color.Alpha{0xff})
+
+	filler := rasterx.NewFiller(w, h, scanner)
+	adder := &rasterx.MatrixAdder{
+		M:     rasterx.Identity.Translate(float64(-offset.X), float64(-offset.Y)),
+		Adder: filler,
+	}
+
+	// Each shape is drawn separately, so that overlapping shapes
+	// with opposite orientations don't cancel each other.
+	for _, shape := range b.shapes {
+		filler.Clear()
+		shape(float64(width), float64(height), adder)
+		filler.Draw()
+	}
+
+	if b.feather > 0 {
+		blurred := image.NewAlpha(dstb)
+		GaussianBlur(b.feather).Apply(blurred, dst, true)
+		draw.Draw(dst, dstb, blurred, dstb.Min, draw.Src)
+	}
+}
+
+// Draws the mask to the dst image.
+// The size of the mask is the size of the dst image.
+// The dst image is cleared before drawing.
+func (b *MaskBuilder) Draw(dst *image.Alpha) {
+	dstb := dst.Bounds()
+	for y := dstb.Min.Y; y < dstb.Max.Y; y++ {
+		i := dst.PixOffset(dstb.Min.X, y)
+		row := dst.Pix[i : i+dstb.Dx()]
+		for x := range row {
+			row[x] = 0
+		}
+	}
+
+	b.rasterize(dst, dstb.Dx(), dstb.Dy(), image.Point{})
+}
+
+// Builds a mask of the given size.
+func (b *MaskBuilder) Build(width, height int) *image.Alpha {
+	mask := image.NewAlpha(image.Rect(0, 0, width, height))
+	b.Draw(mask)
+	return mask
+}
+
+// Returns the bounds of all shapes in a mask of the given size.
+func (b *MaskBuilder) shapesBounds(width, height int) image.Rectangle {
+	ext := newExtentAdder()
+	for _, shape := range b.shapes {
+		shape(float64(width), float64(height), ext)
+	}
+
+	return ext.bounds()
+}
+
+// Makes a closed path from the polygon vertices.
+func polygonPath(points []gm32.Vec2) *Path {
+	path := NewPath()
+	path.MoveTo(points[0][0], points[0][1])
+	for _, p := range points[1:] {
+		path.LineTo(p[0], p[1])
+	}
+	path.Close()
+
+	return path
+}
+
+// Adder, which calculates the extent of a path.
+type extentAdder struct {
+	last           fixed.Point26_6
+	minX, minY     float32
+	maxX, maxY     float32
+	hasPoints      bool
+	curveFlattener func(x, y float32)
+}
+
+func newExtentAdder() *extentAdder {
+	ext := &extentAdder{}
+	ext.curveFlattener = func(x, y float32) {
+		ext.add(x, y)
+	}
+
+	return ext
+}
+
+func (e *extentAdder) add(x, y float32) {
+	if !e.hasPoints {
+		e.minX, e.maxX = x, x
+		e.minY, e.maxY = y, y
+		e.hasPoints = true
+		return
+	}
+
+	e.minX = gm32.Min(e.minX, x)
+	e.minY = gm32.Min(e.minY, y)
+	e.maxX = gm32.Max(e.maxX, x)
+	e.maxY = gm32.Max(e.maxY, y)
+}
+
+func fixedToFloat(p fixed.Point26_6) (x, y float32) {
+	return float32(p.X) / 64, float32(p.Y) / 64
+}
+
+func (e *extentAdder) Start(a fixed.Point26_6) {
+	e.last = a
+	e.add(fixedToFloat(a))
+}
+
+func (e *extentAdder) Line(b fixed.Point26_6) {
+	e.last = b
+	e.add(fixedToFloat(b))
+}
+
+func (e *extentAdder) QuadBezier(b, c fixed.Point26_6) {
+	ax, ay := fixedToFloat(e.last)
+	bx, by := fixedToFloat(b)
+	cx, cy := fixedToFloat(c)
+	rasterx.QuadTo(ax, ay, bx, by, cx, cy, e.curveFlattener)
+	e.Line(c)
+}
+
+func (e *extentAdder) CubeBezier(b, c, d fixed.Point26_6) {
+	ax, ay := fixedToFloat(e.last)
+	bx, by := fixedToFloat(b)
+	cx, cy := fixedToFloat(c)
+	dx, dy := fixedToFloat(d)
+	rasterx.CubeTo(ax, ay, bx, by, cx, cy, dx, dy, e.curveFlattener)
+	e.Line(d)
+}
+
+func (e *extentAdder) Stop(closeLoop bool) {}
+
+// Returns the smallest integer rectangle containing the path.
+func (e *extentAdder) bounds() image.Rectangle {
+	if !e.hasPoints {
+		return image.Rectangle{}
+	}
+
+	return image.Rect(
+		int(gm32.Floor(e.minX)), int(gm32.Floor(e.minY)),
+		int(gm32.Ceil(e.maxX)), int(gm32.Ceil(e.maxY)),
+	)
+}
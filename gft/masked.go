@@ -0,0 +1,168 @@
+package gft
+
+import (
+	"image"
+	"image/draw"
+	"reflect"
+
+	"github.com/infastin/gul/giu/gcu"
+	"github.com/infastin/gul/tools"
+)
+
+type maskedFilter struct {
+	filter Filter
+	mask   image.Image
+	// Comparable identity of the mask.
+	maskKey interface{}
+	invert  bool
+}
+
+type maskKey struct {
+	typ reflect.Type
+	ptr uintptr
+}
+
+// Returns a comparable key, which identifies the mask.
+// Masks are identical, only if they are the same pointer.
+// Other masks (e.g. non-pointer images with slices) are never identical.
+func newMaskKey(mask image.Image) interface{} {
+	v := reflect.ValueOf(mask)
+	if v.Kind() == reflect.Ptr {
+		return maskKey{typ: v.Type(), ptr: v.Pointer()}
+	}
+
+	return new(int)
+}
+
+func (f *maskedFilter) Bounds(src image.Rectangle) image.Rectangle {
+	return f.filter.Bounds(src)
+}
+
+func (f *maskedFilter) Apply(dst draw.Image, src image.Image, parallel bool) {
+	srcb := src.Bounds()
+	dstb := dst.Bounds()
+	maskb := f.mask.Bounds()
+
+	// There are no original pixels to blend with,
+	// if the filter changes the size of an image.
+	if f.filter.Bounds(srcb).Size() != srcb.Size() {
+		f.filter.Apply(dst, src, parallel)
+		return
+	}
+
+	filtered := newPixelImage(f.filter.Bounds(srcb))
+	f.filter.Apply(filtered, src, parallel)
+
+	pixGetter := newPixelGetter(src)
+	maskGetter := newPixelGetter(f.mask)
+	pixSetter := newPixelSetter(dst)
+
+	procs := 1
+	if parallel {
+		procs = 0
+	}
+
+	tools.Parallelize(procs, dstb.Min.Y, dstb.Max.Y, 1, func(start, end int) {
+		for y := start; y < end; y++ {
+			for x := dstb.Min.X; x < dstb.Max.X; x++ {
+				dx := x - dstb.Min.X
+				dy := y - dstb.Min.Y
+
+				m := maskGetter.getPixel(maskb.Min.X+dx, maskb.Min.Y+dy)
+				w := gcu.RGBLuminance(m.R, m.G, m.B) * m.A
+				if f.invert {
+					w = 1 - w
+				}
+
+				pix := pixGetter.getPixel(srcb.Min.X+dx, srcb.Min.Y+dy)
+				fpix := filtered.Pix[filtered.PixOffset(filtered.Rect.Min.X+dx, filtered.Rect.Min.Y+dy)]
+
+				pixSetter.setPixel(x, y, mixPixels(pix, fpix, w).Clamp(0, 1))
+			}
+		}
+	})
+}
+
+func (f *maskedFilter) CanMerge(filter Filter) bool {
+	filt, ok := filter.(*maskedFilter)
+	if !ok || f.maskKey != filt.maskKey || f.invert != filt.invert {
+		return false
+	}
+
+	if fi, ok := f.filter.(MergingFilter); ok {
+		return fi.CanMerge(filt.filter)
+	}
+
+	return false
+}
+
+func (f *maskedFilter) Merge(filter Filter) {
+	filt := filter.(*maskedFilter)
+	f.filter.(MergingFilter).Merge(filt.filter)
+}
+
+func (f *maskedFilter) CanUndo(filter Filter) bool {
+	filt, ok := filter.(*maskedFilter)
+	if !ok || f.maskKey != filt.maskKey || f.invert != filt.invert {
+		return false
+	}
+
+	if fi, ok := f.filter.(MergingFilter); ok {
+		return fi.CanUndo(filt.filter)
+	}
+
+	return false
+}
+
+func (f *maskedFilter) Undo(filter Filter) bool {
+	filt := filter.(*maskedFilter)
+	return f.filter.(MergingFilter).Undo(filt.filter)
+}
+
+func (f *maskedFilter) Skip() bool {
+	if fi, ok := f.filter.(MergingFilter); ok {
+		return fi.Skip()
+	}
+
+	return false
+}
+
+func (f *maskedFilter) Copy() Filter {
+	filter := f.filter
+	if fi, ok := filter.(MergingFilter); ok {
+		filter = fi.Copy()
+	}
+
+	return &maskedFilter{
+		filter:  filter,
+		mask:    f.mask,
+		maskKey: f.maskKey,
+		invert:  f.invert,
+	}
+}
+
+// Applies the filter only where the mask is set.
+// The mask weight of a pixel is its luminance multiplied by its alpha,
+// so both grayscale masks and alpha masks (e.g. *image.Alpha) can be used.
+// Soft masks smoothly blend the filtered image with the original one.
+// If invert is true, the filter is applied where the mask isn't set.
+//
+// The mask is aligned with the top-left corner of an image.
+// Pixels outside of the mask are left unchanged (or filtered, if invert is true).
+//
+// If the filter changes the size of an image (e.g. Scale or Rotate by 90 degrees),
+// there are no original pixels to blend with, so the filtered image is drawn as is.
+//
+// Masked filters with the same mask (the same pointer) and invert flag are merged and undone by the wrapped filter.
+func Masked(filter Filter, mask image.Image, invert bool) MergingFilter {
+	if filter == nil || mask == nil {
+		return nil
+	}
+
+	return &maskedFilter{
+		filter:  filter,
+		mask:    mask,
+		maskKey: newMaskKey(mask),
+		invert:  invert,
+	}
+}
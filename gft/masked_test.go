@@ -0,0 +1,99 @@
+package gft
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func newFilledImage(width, height int, c color.NRGBA) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetNRGBA(x, y, c)
+		}
+	}
+
+	return img
+}
+
+func TestMaskedPreservingBounds(t *testing.T) {
+	red := color.NRGBA{255, 0, 0, 255}
+	src := newFilledImage(10, 10, red)
+
+	// The left half of the mask is set.
+	mask := image.NewAlpha(image.Rect(0, 0, 10, 10))
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 5; x++ {
+			mask.SetAlpha(x, y, color.Alpha{0xff})
+		}
+	}
+
+	for _, invert := range []bool{false, true} {
+		filter := Masked(CombineColorchanFilters(Invert()), mask, invert)
+		if filter == nil {
+			t.Fatalf("Masked returned nil for a bounds-preserving filter")
+		}
+
+		dst := image.NewNRGBA(filter.Bounds(src.Bounds()))
+		filter.Apply(dst, src, true)
+
+		inverted := color.NRGBA{0, 255, 255, 255}
+		left, right := inverted, red
+		if invert {
+			left, right = red, inverted
+		}
+
+		if got := dst.NRGBAAt(2, 5); got != left {
+			t.Errorf("invert=%v: left pixel = %v, expected %v", invert, got, left)
+		}
+		if got := dst.NRGBAAt(7, 5); got != right {
+			t.Errorf("invert=%v: right pixel = %v, expected %v", invert, got, right)
+		}
+	}
+}
+
+func TestMaskedChangingBounds(t *testing.T) {
+	red := color.NRGBA{255, 0, 0, 255}
+	src := newFilledImage(10, 10, red)
+
+	// The mask isn't set, but there are no original pixels to blend with,
+	// so the scaled image must be drawn as is.
+	mask := image.NewAlpha(image.Rect(0, 0, 10, 10))
+
+	filter := Masked(Scale(2, 2, false, BilinearResampling, 1, 1), mask, false)
+	if filter == nil {
+		t.Fatalf("Masked returned nil for a bounds-changing filter")
+	}
+
+	dstb := filter.Bounds(src.Bounds())
+	if dstb.Dx() != 20 || dstb.Dy() != 20 {
+		t.Fatalf("bounds = %v, expected 20x20", dstb)
+	}
+
+	dst := image.NewNRGBA(dstb)
+	filter.Apply(dst, src, true)
+
+	for _, p := range []image.Point{{0, 0}, {5, 5}, {15, 15}, {19, 19}} {
+		if got := dst.NRGBAAt(p.X, p.Y); got != red {
+			t.Errorf("pixel at %v = %v, expected %v", p, got, red)
+		}
+	}
+}
+
+// Non-pointer image with a slice, which isn't comparable.
+type sliceImage struct {
+	*image.Alpha
+	tags []string
+}
+
+func TestMaskedNonComparableMask(t *testing.T) {
+	mask := sliceImage{Alpha: image.NewAlpha(image.Rect(0, 0, 10, 10))}
+
+	f0 := Masked(CombineColorchanFilters(Invert()), mask, false)
+	f1 := Masked(CombineColorchanFilters(Invert()), mask, false)
+
+	if f0.CanMerge(f1) || f0.CanUndo(f1) {
+		t.Errorf("masked filters with non-pointer masks must not be merged")
+	}
+}
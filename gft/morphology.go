@@ -0,0 +1,327 @@
+package gft
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+
+	"github.com/infastin/gul/gm32"
+	"github.com/infastin/gul/tools"
+)
+
+// Defines the neighborhood of a pixel used by morphological filters.
+type StructuringElement struct {
+	offsets []image.Point
+	rect    bool
+	rx, ry  int
+}
+
+func newStructuringElement(rx, ry int, fn func(x, y int) bool) *StructuringElement {
+	se := &StructuringElement{
+		rect: true,
+		rx:   rx,
+		ry:   ry,
+	}
+
+	for y := -ry; y <= ry; y++ {
+		for x := -rx; x <= rx; x++ {
+			if fn(x, y) {
+				se.offsets = append(se.offsets, image.Point{x, y})
+			} else {
+				se.rect = false
+			}
+		}
+	}
+
+	return se
+}
+
+// Creates a square structuring element with the side of 2*radius+1 pixels.
+func SquareElement(radius int) *StructuringElement {
+	if radius < 0 {
+		radius = 0
+	}
+
+	return newStructuringElement(radius, radius, func(x, y int) bool {
+		return true
+	})
+}
+
+// Creates a disk structuring element of a given radius.
+func DiskElement(radius int) *StructuringElement {
+	if radius < 0 {
+		radius = 0
+	}
+
+	r := float32(radius) + 0.5
+	return newStructuringElement(radius, radius, func(x, y int) bool {
+		return gm32.Hypot(float32(x), float32(y)) <= r
+	})
+}
+
+// Creates a cross (plus sign) structuring element with arms of a given radius.
+func CrossElement(radius int) *StructuringElement {
+	if radius < 0 {
+		radius = 0
+	}
+
+	return newStructuringElement(radius, radius, func(x, y int) bool {
+		return x == 0 || y == 0
+	})
+}
+
+// Creates a structuring element from a boolean mask.
+// The mask must have odd number of rows and columns, and all rows must have the same length.
+// The center of the mask is the origin of the structuring element.
+func MaskElement(mask [][]bool) *StructuringElement {
+	height := len(mask)
+	width := 0
+	if height != 0 {
+		width = len(mask[0])
+	}
+
+	if height%2 == 0 || width%2 == 0 {
+		err := fmt.Errorf("the mask must have odd number of rows and columns (got (%dx%d))", height, width)
+		panic(err)
+	}
+
+	for i, row := range mask {
+		if len(row) != width {
+			err := fmt.Errorf("all rows of the mask must have the same length (got %d in row %d, expected %d)", len(row), i, width)
+			panic(err)
+		}
+	}
+
+	rx, ry := width/2, height/2
+	return newStructuringElement(rx, ry, func(x, y int) bool {
+		return mask[y+ry][x+rx]
+	})
+}
+
+// Erodes (if dilate is false) or dilates (if dilate is true) an image
+// using the given neighborhood offsets.
+func morphologyPass(dst draw.Image, src image.Image, offsets []image.Point, dilate, alphaOnly, parallel bool) {
+	srcb := src.Bounds()
+	dstb := dst.Bounds()
+
+	pixGetter := newPixelGetter(src)
+	pixSetter := newPixelSetter(dst)
+
+	pick := gm32.Min
+	if dilate {
+		pick = gm32.Max
+	}
+
+	procs := 1
+	if parallel {
+		procs = 0
+	}
+
+	tools.Parallelize(procs, srcb.Min.Y, srcb.Max.Y, 1, func(start, end int) {
+		for y := start; y < end; y++ {
+			for x := srcb.Min.X; x < srcb.Max.X; x++ {
+				pix := pixGetter.getPixel(x, y)
+				res := pix
+
+				// The center pixel is a part of the neighborhood,
+				// only if the structuring element contains the origin.
+				first := true
+
+				for _, off := range offsets {
+					p := image.Point{x + off.X, y + off.Y}
+					if !p.In(srcb) {
+						continue
+					}
+
+					npix := pixGetter.getPixel(p.X, p.Y)
+					if first {
+						if !alphaOnly {
+							res.R, res.G, res.B = npix.R, npix.G, npix.B
+						}
+						res.A = npix.A
+						first = false
+						continue
+					}
+
+					if !alphaOnly {
+						res.R = pick(res.R, npix.R)
+						res.G = pick(res.G, npix.G)
+						res.B = pick(res.B, npix.B)
+					}
+					res.A = pick(res.A, npix.A)
+				}
+
+				pixSetter.setPixel(dstb.Min.X+x-srcb.Min.X, dstb.Min.Y+y-srcb.Min.Y, res)
+			}
+		}
+	})
+}
+
+func morphology(dst draw.Image, src image.Image, se *StructuringElement, dilate, alphaOnly, parallel bool) {
+	if !se.rect || se.rx == 0 || se.ry == 0 {
+		morphologyPass(dst, src, se.offsets, dilate, alphaOnly, parallel)
+		return
+	}
+
+	// Rectangular elements are separable into a horizontal and a vertical line.
+	row := make([]image.Point, 0, 2*se.rx+1)
+	for x := -se.rx; x <= se.rx; x++ {
+		row = append(row, image.Point{x, 0})
+	}
+
+	col := make([]image.Point, 0, 2*se.ry+1)
+	for y := -se.ry; y <= se.ry; y++ {
+		col = append(col, image.Point{0, y})
+	}
+
+	tmp := newPixelImage(src.Bounds())
+	morphologyPass(tmp, src, row, dilate, alphaOnly, parallel)
+	morphologyPass(dst, tmp, col, dilate, alphaOnly, parallel)
+}
+
+// Writes the difference of the minuend and subtrahend images to dst.
+// If alphaOnly is false, the alpha channel is taken from the src image.
+func morphologyDifference(dst draw.Image, src, minuend, subtrahend image.Image, alphaOnly, parallel bool) {
+	srcb := minuend.Bounds()
+	dstb := dst.Bounds()
+
+	srcGetter := newPixelGetter(src)
+	minGetter := newPixelGetter(minuend)
+	subGetter := newPixelGetter(subtrahend)
+	pixSetter := newPixelSetter(dst)
+
+	procs := 1
+	if parallel {
+		procs = 0
+	}
+
+	tools.Parallelize(procs, srcb.Min.Y, srcb.Max.Y, 1, func(start, end int) {
+		for y := start; y < end; y++ {
+			for x := srcb.Min.X; x < srcb.Max.X; x++ {
+				pix := minGetter.getPixel(x, y)
+				sub := subGetter.getPixel(x, y)
+
+				if alphaOnly {
+					pix.A = gm32.Max(0, pix.A-sub.A)
+				} else {
+					pix.R = gm32.Max(0, pix.R-sub.R)
+					pix.G = gm32.Max(0, pix.G-sub.G)
+					pix.B = gm32.Max(0, pix.B-sub.B)
+					pix.A = srcGetter.getPixel(x, y).A
+				}
+
+				pixSetter.setPixel(dstb.Min.X+x-srcb.Min.X, dstb.Min.Y+y-srcb.Min.Y, pix)
+			}
+		}
+	})
+}
+
+type morphologyOp int
+
+const (
+	erodeOp morphologyOp = iota
+	dilateOp
+	openOp
+	closeOp
+	gradientOp
+	topHatOp
+)
+
+type morphologyFilter struct {
+	op        morphologyOp
+	se        *StructuringElement
+	alphaOnly bool
+}
+
+func (f *morphologyFilter) Bounds(src image.Rectangle) image.Rectangle {
+	return src
+}
+
+func (f *morphologyFilter) Apply(dst draw.Image, src image.Image, parallel bool) {
+	switch f.op {
+	case erodeOp:
+		morphology(dst, src, f.se, false, f.alphaOnly, parallel)
+	case dilateOp:
+		morphology(dst, src, f.se, true, f.alphaOnly, parallel)
+	case openOp:
+		tmp := newPixelImage(src.Bounds())
+		morphology(tmp, src, f.se, false, f.alphaOnly, parallel)
+		morphology(dst, tmp, f.se, true, f.alphaOnly, parallel)
+	case closeOp:
+		tmp := newPixelImage(src.Bounds())
+		morphology(tmp, src, f.se, true, f.alphaOnly, parallel)
+		morphology(dst, tmp, f.se, false, f.alphaOnly, parallel)
+	case gradientOp:
+		dilated := newPixelImage(src.Bounds())
+		eroded := newPixelImage(src.Bounds())
+		morphology(dilated, src, f.se, true, f.alphaOnly, parallel)
+		morphology(eroded, src, f.se, false, f.alphaOnly, parallel)
+		morphologyDifference(dst, src, dilated, eroded, f.alphaOnly, parallel)
+	case topHatOp:
+		eroded := newPixelImage(src.Bounds())
+		opened := newPixelImage(src.Bounds())
+		morphology(eroded, src, f.se, false, f.alphaOnly, parallel)
+		morphology(opened, eroded, f.se, true, f.alphaOnly, parallel)
+		morphologyDifference(dst, src, src, opened, f.alphaOnly, parallel)
+	}
+}
+
+func newMorphologyFilter(op morphologyOp, se *StructuringElement, alphaOnly bool) Filter {
+	if se == nil || len(se.offsets) == 0 {
+		return nil
+	}
+
+	return &morphologyFilter{
+		op:        op,
+		se:        se,
+		alphaOnly: alphaOnly,
+	}
+}
+
+// Replaces each pixel with the minimum of its neighborhood defined by the structuring element.
+// Shrinks bright areas and grows dark ones.
+// If alphaOnly is true, only the alpha channel is changed.
+// Otherwise, each channel is changed independently.
+func Erode(se *StructuringElement, alphaOnly bool) Filter {
+	return newMorphologyFilter(erodeOp, se, alphaOnly)
+}
+
+// Replaces each pixel with the maximum of its neighborhood defined by the structuring element.
+// Grows bright areas and shrinks dark ones.
+// If alphaOnly is true, only the alpha channel is changed.
+// Otherwise, each channel is changed independently.
+func Dilate(se *StructuringElement, alphaOnly bool) Filter {
+	return newMorphologyFilter(dilateOp, se, alphaOnly)
+}
+
+// Erodes and then dilates an image.
+// Removes bright details smaller than the structuring element.
+// If alphaOnly is true, only the alpha channel is changed.
+// Otherwise, each channel is changed independently.
+func Open(se *StructuringElement, alphaOnly bool) Filter {
+	return newMorphologyFilter(openOp, se, alphaOnly)
+}
+
+// Dilates and then erodes an image.
+// Removes dark details smaller than the structuring element.
+// If alphaOnly is true, only the alpha channel is changed.
+// Otherwise, each channel is changed independently.
+func Close(se *StructuringElement, alphaOnly bool) Filter {
+	return newMorphologyFilter(closeOp, se, alphaOnly)
+}
+
+// Calculates the difference between the dilation and the erosion of an image,
+// which gives outlines of objects.
+// If alphaOnly is true, only the alpha channel is changed.
+// Otherwise, each color channel is changed independently and alpha channel is left unchanged.
+func MorphologicalGradient(se *StructuringElement, alphaOnly bool) Filter {
+	return newMorphologyFilter(gradientOp, se, alphaOnly)
+}
+
+// Calculates the difference between an image and its opening,
+// which gives bright details smaller than the structuring element.
+// If alphaOnly is true, only the alpha channel is changed.
+// Otherwise, each color channel is changed independently and alpha channel is left unchanged.
+func TopHat(se *StructuringElement, alphaOnly bool) Filter {
+	return newMorphologyFilter(topHatOp, se, alphaOnly)
+}
@@ -0,0 +1,175 @@
+package gft
+
+import (
+	"image"
+	"image/draw"
+	"math/rand"
+
+	"github.com/infastin/gul/giu/gcu"
+	"github.com/infastin/gul/gm32"
+	"github.com/infastin/gul/tools"
+)
+
+// Kind of noise added by AddNoise.
+type NoiseKind int
+
+const (
+	// Normally distributed noise, the amount is the standard deviation.
+	GaussianNoise NoiseKind = iota
+	// Uniformly distributed noise in the range [-amount, amount].
+	UniformNoise
+	// Replaces random pixels with black or white, the amount is the probability of replacing a pixel.
+	SaltAndPepperNoise
+	// Gaussian noise, which is the strongest in midtones and fades in shadows and highlights,
+	// like the grain of a photographic film.
+	FilmGrainNoise
+)
+
+// Source of pseudo-random numbers (SplitMix64), which is cheap to create,
+// so that every row of an image can have its own stream.
+type rowSource struct {
+	state uint64
+}
+
+func newRowSource(seed int64, row int) *rowSource {
+	src := &rowSource{}
+	src.Seed(seed ^ int64(uint64(row+1)*0x9e3779b97f4a7c15))
+	return src
+}
+
+func (s *rowSource) Seed(seed int64) {
+	s.state = uint64(seed)
+	s.Uint64()
+}
+
+func (s *rowSource) Uint64() uint64 {
+	s.state += 0x9e3779b97f4a7c15
+	z := s.state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+func (s *rowSource) Int63() int64 {
+	return int64(s.Uint64() >> 1)
+}
+
+type noiseFilter struct {
+	kind       NoiseKind
+	amount     float32
+	monochrome bool
+	seed       int64
+}
+
+func (f *noiseFilter) Bounds(src image.Rectangle) image.Rectangle {
+	return image.Rect(0, 0, src.Dx(), src.Dy())
+}
+
+// Returns the noise value added to a channel.
+func (f *noiseFilter) noise(rng *rand.Rand) float32 {
+	switch f.kind {
+	case UniformNoise:
+		return (2*rng.Float32() - 1) * f.amount
+	default:
+		return float32(rng.NormFloat64()) * f.amount
+	}
+}
+
+func (f *noiseFilter) apply(pix Pixel, rng *rand.Rand) Pixel {
+	switch f.kind {
+	case SaltAndPepperNoise:
+		salt := func() float32 {
+			if rng.Float32() >= f.amount {
+				return -1
+			}
+			if rng.Float32() < 0.5 {
+				return 0
+			}
+			return 1
+		}
+
+		if f.monochrome {
+			if v := salt(); v >= 0 {
+				pix.R, pix.G, pix.B = v, v, v
+			}
+			return pix
+		}
+
+		for _, c := range []*float32{&pix.R, &pix.G, &pix.B} {
+			if v := salt(); v >= 0 {
+				*c = v
+			}
+		}
+
+		return pix
+	case FilmGrainNoise:
+		l := gm32.Clamp(gcu.RGBLuminance(pix.R, pix.G, pix.B), 0, 1)
+		k := 2 * gm32.Sqrt(l*(1-l))
+
+		if f.monochrome {
+			n := f.noise(rng) * k
+			pix.R, pix.G, pix.B = pix.R+n, pix.G+n, pix.B+n
+		} else {
+			pix.R += f.noise(rng) * k
+			pix.G += f.noise(rng) * k
+			pix.B += f.noise(rng) * k
+		}
+	default:
+		if f.monochrome {
+			n := f.noise(rng)
+			pix.R, pix.G, pix.B = pix.R+n, pix.G+n, pix.B+n
+		} else {
+			pix.R += f.noise(rng)
+			pix.G += f.noise(rng)
+			pix.B += f.noise(rng)
+		}
+	}
+
+	return pix.Clamp(0, 1)
+}
+
+func (f *noiseFilter) Apply(dst draw.Image, src image.Image, parallel bool) {
+	srcb := src.Bounds()
+	dstb := dst.Bounds()
+
+	pixGetter := newPixelGetter(src)
+	pixSetter := newPixelSetter(dst)
+
+	procs := 1
+	if parallel {
+		procs = 0
+	}
+
+	tools.Parallelize(procs, srcb.Min.Y, srcb.Max.Y, 1, func(start, end int) {
+		for y := start; y < end; y++ {
+			// Each row has its own stream, so the result doesn't depend
+			// on how rows are split between goroutines.
+			rng := rand.New(newRowSource(f.seed, y-srcb.Min.Y))
+
+			for x := srcb.Min.X; x < srcb.Max.X; x++ {
+				pix := f.apply(pixGetter.getPixel(x, y), rng)
+				pixSetter.setPixel(dstb.Min.X+x-srcb.Min.X, dstb.Min.Y+y-srcb.Min.Y, pix)
+			}
+		}
+	})
+}
+
+// Adds noise of the given kind to an image.
+// The amount is relative to the range of color values [0, 1], see NoiseKind.
+// Monochrome noise changes all color channels of a pixel by the same value.
+// The alpha channel is not changed.
+//
+// The result is fully determined by the seed, even if the filter is applied in parallel.
+// Returns nil, if the amount <= 0.
+func AddNoise(kind NoiseKind, amount float32, monochrome bool, seed int64) Filter {
+	if amount <= 0 {
+		return nil
+	}
+
+	return &noiseFilter{
+		kind:       kind,
+		amount:     amount,
+		monochrome: monochrome,
+		seed:       seed,
+	}
+}
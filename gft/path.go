@@ -0,0 +1,413 @@
+package gft
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/srwiley/rasterx"
+	"golang.org/x/image/math/fixed"
+)
+
+type pathOp int
+
+const (
+	pathMoveTo pathOp = iota
+	pathLineTo
+	pathQuadTo
+	pathCubeTo
+	pathArcTo
+	pathClose
+)
+
+type pathCmd struct {
+	op   pathOp
+	args [7]float32
+}
+
+// Vector path made of lines, Bézier curves and elliptical arcs, like SVG paths.
+// All coordinates are relative to the size of an image and usually are in the range [0, 1].
+type Path struct {
+	cmds []pathCmd
+}
+
+func NewPath() *Path {
+	return &Path{}
+}
+
+// Starts a new subpath at the given point.
+func (p *Path) MoveTo(x, y float32) *Path {
+	p.cmds = append(p.cmds, pathCmd{op: pathMoveTo, args: [7]float32{x, y}})
+	return p
+}
+
+// Adds a line from the current point to the given point.
+func (p *Path) LineTo(x, y float32) *Path {
+	p.cmds = append(p.cmds, pathCmd{op: pathLineTo, args: [7]float32{x, y}})
+	return p
+}
+
+// Adds a quadratic Bézier curve from the current point to the point (x, y)
+// with the control point (cx, cy).
+func (p *Path) QuadTo(cx, cy, x, y float32) *Path {
+	p.cmds = append(p.cmds, pathCmd{op: pathQuadTo, args: [7]float32{cx, cy, x, y}})
+	return p
+}
+
+// Adds a cubic Bézier curve from the current point to the point (x, y)
+// with the control points (c1x, c1y) and (c2x, c2y).
+func (p *Path) CubeTo(c1x, c1y, c2x, c2y, x, y float32) *Path {
+	p.cmds = append(p.cmds, pathCmd{op: pathCubeTo, args: [7]float32{c1x, c1y, c2x, c2y, x, y}})
+	return p
+}
+
+// Adds an elliptical arc from the current point to the point (x, y).
+// The parameters have the same meaning as in the SVG arc command:
+// rx and ry are the radii, rot is the rotation of the ellipse in degrees,
+// largeArc and sweep select one of the four possible arcs.
+// The rx radius is relative to the width of an image and the ry radius is relative to the height of an image.
+func (p *Path) ArcTo(rx, ry, rot float32, largeArc, sweep bool, x, y float32) *Path {
+	var large, sw float32
+	if largeArc {
+		large = 1
+	}
+	if sweep {
+		sw = 1
+	}
+
+	p.cmds = append(p.cmds, pathCmd{op: pathArcTo, args: [7]float32{rx, ry, rot, large, sw, x, y}})
+	return p
+}
+
+// Closes the current subpath.
+func (p *Path) Close() *Path {
+	p.cmds = append(p.cmds, pathCmd{op: pathClose})
+	return p
+}
+
+// Adds the path scaled by (sx, sy) to the adder.
+func (p *Path) addTo(sx, sy float64, adder rasterx.Adder) {
+	var startX, startY, lastX, lastY float64
+	started := false
+
+	point := func(x, y float32) fixed.Point26_6 {
+		return rasterx.ToFixedP(float64(x)*sx, float64(y)*sy)
+	}
+
+	for _, cmd := range p.cmds {
+		a := cmd.args
+
+		if !started && cmd.op != pathMoveTo && cmd.op != pathClose {
+			adder.Start(rasterx.ToFixedP(lastX, lastY))
+			startX, startY = lastX, lastY
+			started = true
+		}
+
+		switch cmd.op {
+		case pathMoveTo:
+			if started {
+				adder.Stop(false)
+			}
+
+			lastX, lastY = float64(a[0])*sx, float64(a[1])*sy
+			startX, startY = lastX, lastY
+			adder.Start(rasterx.ToFixedP(lastX, lastY))
+			started = true
+		case pathLineTo:
+			adder.Line(point(a[0], a[1]))
+			lastX, lastY = float64(a[0])*sx, float64(a[1])*sy
+		case pathQuadTo:
+			adder.QuadBezier(point(a[0], a[1]), point(a[2], a[3]))
+			lastX, lastY = float64(a[2])*sx, float64(a[3])*sy
+		case pathCubeTo:
+			adder.CubeBezier(point(a[0], a[1]), point(a[2], a[3]), point(a[4], a[5]))
+			lastX, lastY = float64(a[4])*sx, float64(a[5])*sy
+		case pathArcTo:
+			x, y := float64(a[5])*sx, float64(a[6])*sy
+			rx, ry := math.Abs(float64(a[0])*sx), math.Abs(float64(a[1])*sy)
+
+			if rx == 0 || ry == 0 {
+				adder.Line(rasterx.ToFixedP(x, y))
+			} else {
+				largeArc, sweep := a[3] != 0, a[4] != 0
+				rot := float64(a[2])
+
+				cx, cy := rasterx.FindEllipseCenter(&rx, &ry, rot*math.Pi/180, lastX, lastY, x, y, sweep, !largeArc)
+				rasterx.AddArc([]float64{rx, ry, rot, float64(a[3]), float64(a[4]), x, y}, cx, cy, lastX, lastY, adder)
+			}
+
+			lastX, lastY = x, y
+		case pathClose:
+			if started {
+				adder.Stop(true)
+				started = false
+			}
+
+			lastX, lastY = startX, startY
+		}
+	}
+
+	if started {
+		adder.Stop(false)
+	}
+}
+
+type pathParser struct {
+	d   string
+	pos int
+}
+
+func (p *pathParser) skipSeparators() {
+	for p.pos < len(p.d) {
+		switch p.d[p.pos] {
+		case ' ', '\t', '\n', '\r', ',':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (p *pathParser) done() bool {
+	p.skipSeparators()
+	return p.pos >= len(p.d)
+}
+
+// Returns true, if the next token is a number.
+func (p *pathParser) hasNumber() bool {
+	if p.done() {
+		return false
+	}
+
+	c := p.d[p.pos]
+	return c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9')
+}
+
+func (p *pathParser) number() (float32, error) {
+	if !p.hasNumber() {
+		return 0, fmt.Errorf("expected a number at position %d", p.pos)
+	}
+
+	start := p.pos
+	if c := p.d[p.pos]; c == '-' || c == '+' {
+		p.pos++
+	}
+
+	seenDot, seenExp := false, false
+
+scan:
+	for p.pos < len(p.d) {
+		c := p.d[p.pos]
+
+		switch {
+		case c >= '0' && c <= '9':
+		case c == '.' && !seenDot && !seenExp:
+			seenDot = true
+		case (c == 'e' || c == 'E') && !seenExp:
+			seenExp = true
+			if p.pos+1 < len(p.d) && (p.d[p.pos+1] == '-' || p.d[p.pos+1] == '+') {
+				p.pos++
+			}
+		default:
+			break scan
+		}
+
+		p.pos++
+	}
+
+	v, err := strconv.ParseFloat(p.d[start:p.pos], 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q at position %d", p.d[start:p.pos], start)
+	}
+
+	return float32(v), nil
+}
+
+// Parses an arc flag, which may be not separated from the next token.
+func (p *pathParser) flag() (bool, error) {
+	if p.done() {
+		return false, fmt.Errorf("expected a flag at position %d", p.pos)
+	}
+
+	switch p.d[p.pos] {
+	case '0':
+		p.pos++
+		return false, nil
+	case '1':
+		p.pos++
+		return true, nil
+	}
+
+	return false, fmt.Errorf("invalid flag %q at position %d", p.d[p.pos], p.pos)
+}
+
+func (p *pathParser) numbers(n int) ([]float32, error) {
+	nums := make([]float32, n)
+	for i := range nums {
+		v, err := p.number()
+		if err != nil {
+			return nil, err
+		}
+		nums[i] = v
+	}
+
+	return nums, nil
+}
+
+// Parses SVG path data (the "d" attribute of the path element).
+// All commands are supported: M, L, H, V, C, S, Q, T, A, Z and their relative versions.
+// The coordinates must be relative to the size of an image, like in other Path methods.
+// Example: ParsePath("M 0.5 0 L 1 1 L 0 1 Z") makes a triangle.
+func ParsePath(d string) (*Path, error) {
+	path := NewPath()
+	parser := &pathParser{d: strings.TrimSpace(d)}
+
+	var curX, curY, startX, startY float32
+	// The last control point used by smooth curves.
+	var ctrlX, ctrlY float32
+	var cmd, prevCmd byte
+
+	for !parser.done() {
+		if !parser.hasNumber() {
+			cmd = parser.d[parser.pos]
+			parser.pos++
+		} else if cmd == 0 || cmd == 'Z' || cmd == 'z' {
+			return nil, fmt.Errorf("expected a command at position %d", parser.pos)
+		}
+
+		rel := cmd >= 'a' && cmd <= 'z'
+		var ox, oy float32
+		if rel {
+			ox, oy = curX, curY
+		}
+
+		switch cmd {
+		case 'M', 'm':
+			v, err := parser.numbers(2)
+			if err != nil {
+				return nil, err
+			}
+
+			curX, curY = ox+v[0], oy+v[1]
+			startX, startY = curX, curY
+			path.MoveTo(curX, curY)
+
+			// Subsequent pairs of coordinates are implicit line commands.
+			if rel {
+				cmd = 'l'
+			} else {
+				cmd = 'L'
+			}
+		case 'L', 'l':
+			v, err := parser.numbers(2)
+			if err != nil {
+				return nil, err
+			}
+
+			curX, curY = ox+v[0], oy+v[1]
+			path.LineTo(curX, curY)
+		case 'H', 'h':
+			v, err := parser.number()
+			if err != nil {
+				return nil, err
+			}
+
+			curX = ox + v
+			path.LineTo(curX, curY)
+		case 'V', 'v':
+			v, err := parser.number()
+			if err != nil {
+				return nil, err
+			}
+
+			curY = oy + v
+			path.LineTo(curX, curY)
+		case 'C', 'c', 'S', 's':
+			var c1x, c1y float32
+			var v []float32
+			var err error
+
+			if cmd == 'C' || cmd == 'c' {
+				v, err = parser.numbers(6)
+				if err != nil {
+					return nil, err
+				}
+
+				c1x, c1y = ox+v[0], oy+v[1]
+				v = v[2:]
+			} else {
+				v, err = parser.numbers(4)
+				if err != nil {
+					return nil, err
+				}
+
+				c1x, c1y = curX, curY
+				if strings.IndexByte("CcSs", prevCmd) >= 0 {
+					c1x, c1y = 2*curX-ctrlX, 2*curY-ctrlY
+				}
+			}
+
+			ctrlX, ctrlY = ox+v[0], oy+v[1]
+			curX, curY = ox+v[2], oy+v[3]
+			path.CubeTo(c1x, c1y, ctrlX, ctrlY, curX, curY)
+		case 'Q', 'q', 'T', 't':
+			if cmd == 'Q' || cmd == 'q' {
+				v, err := parser.numbers(4)
+				if err != nil {
+					return nil, err
+				}
+
+				ctrlX, ctrlY = ox+v[0], oy+v[1]
+				curX, curY = ox+v[2], oy+v[3]
+			} else {
+				v, err := parser.numbers(2)
+				if err != nil {
+					return nil, err
+				}
+
+				if strings.IndexByte("QqTt", prevCmd) >= 0 {
+					ctrlX, ctrlY = 2*curX-ctrlX, 2*curY-ctrlY
+				} else {
+					ctrlX, ctrlY = curX, curY
+				}
+
+				curX, curY = ox+v[0], oy+v[1]
+			}
+
+			path.QuadTo(ctrlX, ctrlY, curX, curY)
+		case 'A', 'a':
+			v, err := parser.numbers(3)
+			if err != nil {
+				return nil, err
+			}
+
+			largeArc, err := parser.flag()
+			if err != nil {
+				return nil, err
+			}
+
+			sweep, err := parser.flag()
+			if err != nil {
+				return nil, err
+			}
+
+			end, err := parser.numbers(2)
+			if err != nil {
+				return nil, err
+			}
+
+			curX, curY = ox+end[0], oy+end[1]
+			path.ArcTo(v[0], v[1], v[2], largeArc, sweep, curX, curY)
+		case 'Z', 'z':
+			curX, curY = startX, startY
+			path.Close()
+		default:
+			return nil, fmt.Errorf("unknown command %q at position %d", cmd, parser.pos-1)
+		}
+
+		prevCmd = cmd
+	}
+
+	return path, nil
+}
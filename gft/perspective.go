@@ -0,0 +1,145 @@
+package gft
+
+import (
+	"image"
+	"image/draw"
+
+	"github.com/infastin/gul/gm32"
+	"github.com/infastin/gul/tools"
+)
+
+// Calculates the homography matrix, which maps the from points to the to points.
+// Returns false, if there is no such matrix (e.g. three points lie on the same line).
+func homography(from, to [4]gm32.Vec2) (gm32.Mat3, bool) {
+	a := gm32.NewMat(8, 8)()
+	b := gm32.NewVec(8)()
+
+	for i := 0; i < 4; i++ {
+		x, y := from[i][0], from[i][1]
+		u, v := to[i][0], to[i][1]
+
+		copy(a.Data[(2*i)*8:], []float32{x, y, 1, 0, 0, 0, -u * x, -u * y})
+		copy(a.Data[(2*i+1)*8:], []float32{0, 0, 0, x, y, 1, -v * x, -v * y})
+
+		b.Data[2*i] = u
+		b.Data[2*i+1] = v
+	}
+
+	h, ok := a.Solve(b)
+	if !ok {
+		return gm32.Mat3{}, false
+	}
+
+	return gm32.Mat3{
+		h.Data[0], h.Data[1], h.Data[2],
+		h.Data[3], h.Data[4], h.Data[5],
+		h.Data[6], h.Data[7], 1,
+	}, true
+}
+
+type perspectiveFilter struct {
+	// Maps the relative coordinates of the result to the relative coordinates of the source.
+	inv           gm32.Mat3
+	dst           [4]gm32.Vec2
+	interpolation Interpolation
+}
+
+// Returns the bounds of the warped quadrilateral.
+func (f *perspectiveFilter) quadBounds(width, height float32) (minX, minY, maxX, maxY float32) {
+	minX, maxX = f.dst[0][0], f.dst[0][0]
+	minY, maxY = f.dst[0][1], f.dst[0][1]
+
+	for _, p := range f.dst[1:] {
+		minX = gm32.Min(minX, p[0])
+		minY = gm32.Min(minY, p[1])
+		maxX = gm32.Max(maxX, p[0])
+		maxY = gm32.Max(maxY, p[1])
+	}
+
+	return minX * width, minY * height, maxX * width, maxY * height
+}
+
+func (f *perspectiveFilter) Bounds(src image.Rectangle) image.Rectangle {
+	srcb := src.Bounds()
+	minX, minY, maxX, maxY := f.quadBounds(float32(srcb.Dx()), float32(srcb.Dy()))
+
+	dstWidth := int(gm32.Round(maxX - minX))
+	dstHeight := int(gm32.Round(maxY - minY))
+
+	return image.Rect(0, 0, dstWidth, dstHeight)
+}
+
+func (f *perspectiveFilter) Apply(dst draw.Image, src image.Image, parallel bool) {
+	srcb := src.Bounds()
+	dstb := dst.Bounds()
+
+	srcWidth := float32(srcb.Dx())
+	srcHeight := float32(srcb.Dy())
+	minX, minY, _, _ := f.quadBounds(srcWidth, srcHeight)
+
+	// Points outside of the image are transparent,
+	// so edge pixels are only used for interpolation near the edges.
+	pixGetter := newEdgePixelGetter(src, EdgeClamp)
+	pixSetter := newPixelSetter(dst)
+
+	procs := 1
+	if parallel {
+		procs = 0
+	}
+
+	tools.Parallelize(procs, dstb.Min.Y, dstb.Max.Y, 1, func(start, end int) {
+		for yi := start; yi < end; yi++ {
+			for xi := dstb.Min.X; xi < dstb.Max.X; xi++ {
+				x := (float32(xi-dstb.Min.X) + 0.5 + minX) / srcWidth
+				y := (float32(yi-dstb.Min.Y) + 0.5 + minY) / srcHeight
+
+				p := f.inv.MulMat3x1(gm32.Vec3{x, y, 1})
+				if p[2] <= 0 {
+					pixSetter.setPixel(xi, yi, Pixel{0, 0, 0, 0})
+					continue
+				}
+
+				sx := p[0] / p[2] * srcWidth
+				sy := p[1] / p[2] * srcHeight
+
+				if sx < 0 || sy < 0 || sx > srcWidth || sy > srcHeight {
+					pixSetter.setPixel(xi, yi, Pixel{0, 0, 0, 0})
+					continue
+				}
+
+				x2 := sx - 0.5 + float32(srcb.Min.X)
+				y2 := sy - 0.5 + float32(srcb.Min.Y)
+
+				rgba := interpolate(pixGetter, x2, y2, f.interpolation)
+				pixSetter.setPixel(xi, yi, rgba)
+			}
+		}
+	})
+}
+
+// Warps an image with a perspective transformation (homography),
+// which maps the src quadrilateral to the dst quadrilateral, using given interpolation method.
+// The coordinates of the vertices are relative to the size of the image and usually are in the range [0, 1].
+// The vertices of both quadrilaterals must be in the same order.
+// The resulting image is the bounding box of the dst quadrilateral.
+//
+// Example: to deskew a photographed document, pass the corners of the document as src
+// and the corners of the whole image {{0, 0}, {1, 0}, {1, 1}, {0, 1}} as dst.
+//
+// Returns nil, if the transformation doesn't exist (e.g. three vertices lie on the same line).
+func Perspective(src, dst [4]gm32.Vec2, interpolation Interpolation) Filter {
+	if src == dst {
+		return nil
+	}
+
+	inv, ok := homography(dst, src)
+	if !ok {
+		return nil
+	}
+
+	return &perspectiveFilter{
+		inv:           inv,
+		dst:           dst,
+		interpolation: interpolation,
+	}
+}
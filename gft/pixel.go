@@ -8,21 +8,135 @@ import (
 	"github.com/infastin/gul/gm32"
 )
 
-type pixel struct {
-	r, g, b, a float32
+// Pixel is a color with non-premultiplied (straight) alpha.
+// Each channel is usually in the range [0, 1].
+type Pixel struct {
+	R, G, B, A float32
 }
 
-func (p pixel) clampPixel(min, max float32) pixel {
-	p.r = gm32.Clamp(p.r, min, max)
-	p.g = gm32.Clamp(p.g, min, max)
-	p.b = gm32.Clamp(p.b, min, max)
-	p.a = gm32.Clamp(p.a, min, max)
+// Returns the color channels of the pixel.
+func (p Pixel) Elem() (r, g, b, a float32) {
+	return p.R, p.G, p.B, p.A
+}
+
+// Returns the pixel with each channel clamped to the range [min, max].
+func (p Pixel) Clamp(min, max float32) Pixel {
+	p.R = gm32.Clamp(p.R, min, max)
+	p.G = gm32.Clamp(p.G, min, max)
+	p.B = gm32.Clamp(p.B, min, max)
+	p.A = gm32.Clamp(p.A, min, max)
 	return p
 }
 
+// Implements color.Color interface.
+func (p Pixel) RGBA() (r, g, b, a uint32) {
+	p = p.Clamp(0, 1)
+	fa := p.A * 0xffff
+
+	r = uint32(f32u16(p.R * fa))
+	g = uint32(f32u16(p.G * fa))
+	b = uint32(f32u16(p.B * fa))
+	a = uint32(f32u16(fa))
+
+	return
+}
+
+// Linearly interpolates between two pixels with premultiplied alpha.
+// The t parameter must be in the range [0, 1].
+func mixPixels(p0, p1 Pixel, t float32) Pixel {
+	a := p0.A + (p1.A-p0.A)*t
+	if a <= 0 {
+		return Pixel{0, 0, 0, 0}
+	}
+
+	w0 := p0.A * (1 - t)
+	w1 := p1.A * t
+	q := 1 / a
+
+	return Pixel{
+		R: (p0.R*w0 + p1.R*w1) * q,
+		G: (p0.G*w0 + p1.G*w1) * q,
+		B: (p0.B*w0 + p1.B*w1) * q,
+		A: a,
+	}
+}
+
+var (
+	// Converts any color to Pixel.
+	PixelModel color.Model = color.ModelFunc(pixelModel)
+)
+
+func pixelModel(c color.Color) color.Color {
+	if _, ok := c.(Pixel); ok {
+		return c
+	}
+
+	return pixelFromColor(c)
+}
+
+// Specifies how pixels outside of an image bounds are obtained.
+type EdgeMode int
+
+const (
+	// Pixels outside of an image are transparent black.
+	EdgeTransparent EdgeMode = iota
+
+	// Pixels outside of an image are copies of the nearest edge pixels.
+	EdgeClamp
+
+	// An image is repeated infinitely in each direction.
+	EdgeWrap
+
+	// An image is mirrored at each edge.
+	EdgeMirror
+)
+
+// In-memory image of Pixels.
+// Used to store intermediate results without loss of precision.
+type pixelImage struct {
+	Pix  []Pixel
+	Rect image.Rectangle
+}
+
+func newPixelImage(r image.Rectangle) *pixelImage {
+	return &pixelImage{
+		Pix:  make([]Pixel, r.Dx()*r.Dy()),
+		Rect: r,
+	}
+}
+
+func (img *pixelImage) ColorModel() color.Model {
+	return PixelModel
+}
+
+func (img *pixelImage) Bounds() image.Rectangle {
+	return img.Rect
+}
+
+func (img *pixelImage) PixOffset(x, y int) int {
+	return (y-img.Rect.Min.Y)*img.Rect.Dx() + (x - img.Rect.Min.X)
+}
+
+func (img *pixelImage) At(x, y int) color.Color {
+	if !(image.Point{x, y}.In(img.Rect)) {
+		return Pixel{}
+	}
+
+	return img.Pix[img.PixOffset(x, y)]
+}
+
+func (img *pixelImage) Set(x, y int, c color.Color) {
+	if !(image.Point{x, y}.In(img.Rect)) {
+		return
+	}
+
+	img.Pix[img.PixOffset(x, y)] = PixelModel.Convert(c).(Pixel)
+}
+
 type pixelGetter struct {
 	img    image.Image
 	bounds image.Rectangle
+	edge   EdgeMode
 }
 
 const (
@@ -31,25 +145,25 @@ const (
 	epal = qf16 * qf16 / 2
 )
 
-func pixelFromColor(c color.Color) (pix pixel) {
+func pixelFromColor(c color.Color) (pix Pixel) {
 	r, g, b, a := c.RGBA()
 	switch a {
 	case 0:
-		pix = pixel{0, 0, 0, 0}
+		pix = Pixel{0, 0, 0, 0}
 	case 0xffff:
-		pix = pixel{
-			r: float32(r) * qf16,
-			g: float32(g) * qf16,
-			b: float32(b) * qf16,
-			a: 1,
+		pix = Pixel{
+			R: float32(r) * qf16,
+			G: float32(g) * qf16,
+			B: float32(b) * qf16,
+			A: 1,
 		}
 	default:
 		q := float32(1) / float32(a)
-		pix = pixel{
-			r: float32(r) * q,
-			g: float32(g) * q,
-			b: float32(b) * q,
-			a: float32(a) * qf16,
+		pix = Pixel{
+			R: float32(r) * q,
+			G: float32(g) * q,
+			B: float32(b) * q,
+			A: float32(a) * qf16,
 		}
 	}
 
@@ -65,32 +179,80 @@ func newPixelGetter(img image.Image) *pixelGetter {
 	return pixGetter
 }
 
-func (p *pixelGetter) getPixel(x, y int) pixel {
+func newEdgePixelGetter(img image.Image, edge EdgeMode) *pixelGetter {
+	pixGetter := &pixelGetter{
+		img:    img,
+		bounds: img.Bounds(),
+		edge:   edge,
+	}
+
+	return pixGetter
+}
+
+func edgeCoord(v, min, max int, edge EdgeMode) int {
+	size := max - min
+
+	switch edge {
+	case EdgeClamp:
+		if v < min {
+			return min
+		}
+		if v >= max {
+			return max - 1
+		}
+	case EdgeWrap:
+		v = (v - min) % size
+		if v < 0 {
+			v += size
+		}
+		return v + min
+	case EdgeMirror:
+		v = (v - min) % (2 * size)
+		if v < 0 {
+			v += 2 * size
+		}
+		if v >= size {
+			v = 2*size - 1 - v
+		}
+		return v + min
+	}
+
+	return v
+}
+
+func (p *pixelGetter) getPixel(x, y int) Pixel {
 	if !(image.Point{x, y}.In(p.bounds)) {
-		return pixel{0, 0, 0, 0}
+		if p.edge == EdgeTransparent || p.bounds.Empty() {
+			return Pixel{0, 0, 0, 0}
+		}
+
+		x = edgeCoord(x, p.bounds.Min.X, p.bounds.Max.X, p.edge)
+		y = edgeCoord(y, p.bounds.Min.Y, p.bounds.Max.Y, p.edge)
 	}
 
 	switch img := p.img.(type) {
+	case *pixelImage:
+		return img.Pix[img.PixOffset(x, y)]
 	case *image.RGBA:
 		i := img.PixOffset(x, y)
 		a := img.Pix[i+3]
 		switch a {
 		case 0:
-			return pixel{0, 0, 0, 0}
+			return Pixel{0, 0, 0, 0}
 		case 0xff:
-			return pixel{
-				r: float32(img.Pix[i]) * qf8,
-				g: float32(img.Pix[i+1]) * qf8,
-				b: float32(img.Pix[i+2]) * qf8,
-				a: 1,
+			return Pixel{
+				R: float32(img.Pix[i]) * qf8,
+				G: float32(img.Pix[i+1]) * qf8,
+				B: float32(img.Pix[i+2]) * qf8,
+				A: 1,
 			}
 		default:
 			q := float32(1) / float32(a)
-			return pixel{
-				r: float32(img.Pix[i]) * q,
-				g: float32(img.Pix[i+1]) * q,
-				b: float32(img.Pix[i+2]) * q,
-				a: float32(a) * qf8,
+			return Pixel{
+				R: float32(img.Pix[i]) * q,
+				G: float32(img.Pix[i+1]) * q,
+				B: float32(img.Pix[i+2]) * q,
+				A: float32(a) * qf8,
 			}
 		}
 	case *image.RGBA64:
@@ -98,55 +260,55 @@ func (p *pixelGetter) getPixel(x, y int) pixel {
 		a := uint16(img.Pix[i+6])<<8 | uint16(img.Pix[i+7])
 		switch a {
 		case 0:
-			return pixel{0, 0, 0, 0}
+			return Pixel{0, 0, 0, 0}
 		case 0xffff:
-			return pixel{
-				r: float32(uint16(img.Pix[i])<<8|uint16(img.Pix[i+1])) * qf16,
-				g: float32(uint16(img.Pix[i+2])<<8|uint16(img.Pix[i+3])) * qf16,
-				b: float32(uint16(img.Pix[i+4])<<8|uint16(img.Pix[i+5])) * qf16,
-				a: 1,
+			return Pixel{
+				R: float32(uint16(img.Pix[i])<<8|uint16(img.Pix[i+1])) * qf16,
+				G: float32(uint16(img.Pix[i+2])<<8|uint16(img.Pix[i+3])) * qf16,
+				B: float32(uint16(img.Pix[i+4])<<8|uint16(img.Pix[i+5])) * qf16,
+				A: 1,
 			}
 		default:
 			q := float32(1) / float32(a)
-			return pixel{
-				r: float32(uint16(img.Pix[i])<<8|uint16(img.Pix[i+1])) * q,
-				g: float32(uint16(img.Pix[i+2])<<8|uint16(img.Pix[i+3])) * q,
-				b: float32(uint16(img.Pix[i+4])<<8|uint16(img.Pix[i+5])) * q,
-				a: float32(a) * qf16,
+			return Pixel{
+				R: float32(uint16(img.Pix[i])<<8|uint16(img.Pix[i+1])) * q,
+				G: float32(uint16(img.Pix[i+2])<<8|uint16(img.Pix[i+3])) * q,
+				B: float32(uint16(img.Pix[i+4])<<8|uint16(img.Pix[i+5])) * q,
+				A: float32(a) * qf16,
 			}
 		}
 	case *image.NRGBA:
 		i := img.PixOffset(x, y)
-		return pixel{
-			r: float32(img.Pix[i]) * qf8,
-			g: float32(img.Pix[i+1]) * qf8,
-			b: float32(img.Pix[i+2]) * qf8,
-			a: float32(img.Pix[i+3]) * qf8,
+		return Pixel{
+			R: float32(img.Pix[i]) * qf8,
+			G: float32(img.Pix[i+1]) * qf8,
+			B: float32(img.Pix[i+2]) * qf8,
+			A: float32(img.Pix[i+3]) * qf8,
 		}
 	case *image.NRGBA64:
 		i := img.PixOffset(x, y)
-		return pixel{
-			r: float32(uint16(img.Pix[i])<<8|uint16(img.Pix[i+1])) * qf16,
-			g: float32(uint16(img.Pix[i+2])<<8|uint16(img.Pix[i+3])) * qf16,
-			b: float32(uint16(img.Pix[i+4])<<8|uint16(img.Pix[i+5])) * qf16,
-			a: float32(uint16(img.Pix[i+6])<<8|uint16(img.Pix[i+7])) * qf16,
+		return Pixel{
+			R: float32(uint16(img.Pix[i])<<8|uint16(img.Pix[i+1])) * qf16,
+			G: float32(uint16(img.Pix[i+2])<<8|uint16(img.Pix[i+3])) * qf16,
+			B: float32(uint16(img.Pix[i+4])<<8|uint16(img.Pix[i+5])) * qf16,
+			A: float32(uint16(img.Pix[i+6])<<8|uint16(img.Pix[i+7])) * qf16,
 		}
 	case *image.Gray:
 		i := img.PixOffset(x, y)
 		v := float32(img.Pix[i]) * qf8
-		return pixel{v, v, v, 1}
+		return Pixel{v, v, v, 1}
 	case *image.Gray16:
 		i := img.PixOffset(x, y)
 		v := float32(uint16(img.Pix[i])<<8|uint16(img.Pix[i+1])) * qf16
-		return pixel{v, v, v, 1}
+		return Pixel{v, v, v, 1}
 	default:
 		return pixelFromColor(p.img.At(x, y))
 	}
 }
 
-func (p *pixelGetter) average(xmin, ymin, xmax, ymax int) pixel {
+func (p *pixelGetter) average(xmin, ymin, xmax, ymax int) Pixel {
 	if xmin >= p.bounds.Max.X || ymin >= p.bounds.Max.Y {
-		return pixel{0, 0, 0, 0}
+		return Pixel{0, 0, 0, 0}
 	}
 
 	if xmax >= p.bounds.Max.X {
@@ -161,33 +323,33 @@ func (p *pixelGetter) average(xmin, ymin, xmax, ymax int) pixel {
 	diffY := ymax - ymin + 1
 	pixNum := float32(diffX * diffY)
 
-	avg := pixel{}
+	avg := Pixel{}
 	for y := ymin; y <= ymax; y++ {
 		for x := xmin; x <= xmax; x++ {
 			pix := p.getPixel(x, y)
-			avg.r += pix.r
-			avg.g += pix.g
-			avg.b += pix.b
-			avg.a += pix.a
+			avg.R += pix.R
+			avg.G += pix.G
+			avg.B += pix.B
+			avg.A += pix.A
 		}
 	}
 
-	avg.r /= pixNum
-	avg.g /= pixNum
-	avg.b /= pixNum
-	avg.a /= pixNum
+	avg.R /= pixNum
+	avg.G /= pixNum
+	avg.B /= pixNum
+	avg.A /= pixNum
 
 	return avg
 }
 
-func (p *pixelGetter) getPixelRow(y int, buf *[]pixel) {
+func (p *pixelGetter) getPixelRow(y int, buf *[]Pixel) {
 	*buf = (*buf)[:0]
 	for x := p.bounds.Min.X; x < p.bounds.Max.X; x++ {
 		*buf = append(*buf, p.getPixel(x, y))
 	}
 }
 
-func (p *pixelGetter) getPixelColumn(x int, buf *[]pixel) {
+func (p *pixelGetter) getPixelColumn(x int, buf *[]Pixel) {
 	*buf = (*buf)[:0]
 	for y := p.bounds.Min.Y; y < p.bounds.Max.Y; y++ {
 		*buf = append(*buf, p.getPixel(x, y))
@@ -208,26 +370,28 @@ func newPixelSetter(img draw.Image) *pixelSetter {
 	return pixSetter
 }
 
-func (p *pixelSetter) setPixel(x, y int, pix pixel) {
+func (p *pixelSetter) setPixel(x, y int, pix Pixel) {
 	if !(image.Point{x, y}.In(p.bounds)) {
 		return
 	}
 
 	switch img := p.img.(type) {
+	case *pixelImage:
+		img.Pix[img.PixOffset(x, y)] = pix
 	case *image.RGBA:
-		fa := pix.a * 0xff
+		fa := pix.A * 0xff
 		i := img.PixOffset(x, y)
-		img.Pix[i] = f32u8(pix.r * fa)
-		img.Pix[i+1] = f32u8(pix.g * fa)
-		img.Pix[i+2] = f32u8(pix.b * fa)
+		img.Pix[i] = f32u8(pix.R * fa)
+		img.Pix[i+1] = f32u8(pix.G * fa)
+		img.Pix[i+2] = f32u8(pix.B * fa)
 		img.Pix[i+3] = f32u8(fa)
 	case *image.RGBA64:
-		fa := pix.a * 0xffff
+		fa := pix.A * 0xffff
 		i := img.PixOffset(x, y)
 
-		r16 := f32u16(pix.r * fa)
-		g16 := f32u16(pix.g * fa)
-		b16 := f32u16(pix.b * fa)
+		r16 := f32u16(pix.R * fa)
+		g16 := f32u16(pix.G * fa)
+		b16 := f32u16(pix.B * fa)
 		a16 := f32u16(fa)
 
 		img.Pix[i] = uint8(r16 >> 8)
@@ -240,17 +404,17 @@ func (p *pixelSetter) setPixel(x, y int, pix pixel) {
 		img.Pix[i+7] = uint8(a16 & 8)
 	case *image.NRGBA:
 		i := img.PixOffset(x, y)
-		img.Pix[i] = f32u8(pix.r * 0xff)
-		img.Pix[i+1] = f32u8(pix.g * 0xff)
-		img.Pix[i+2] = f32u8(pix.b * 0xff)
-		img.Pix[i+3] = f32u8(pix.a * 0xff)
+		img.Pix[i] = f32u8(pix.R * 0xff)
+		img.Pix[i+1] = f32u8(pix.G * 0xff)
+		img.Pix[i+2] = f32u8(pix.B * 0xff)
+		img.Pix[i+3] = f32u8(pix.A * 0xff)
 	case *image.NRGBA64:
 		i := img.PixOffset(x, y)
 
-		r16 := f32u16(pix.r * 0xffff)
-		g16 := f32u16(pix.g * 0xffff)
-		b16 := f32u16(pix.b * 0xffff)
-		a16 := f32u16(pix.a * 0xffff)
+		r16 := f32u16(pix.R * 0xffff)
+		g16 := f32u16(pix.G * 0xffff)
+		b16 := f32u16(pix.B * 0xffff)
+		a16 := f32u16(pix.A * 0xffff)
 
 		img.Pix[i] = uint8(r16 >> 8)
 		img.Pix[i+1] = uint8(r16 & 8)
@@ -262,34 +426,34 @@ func (p *pixelSetter) setPixel(x, y int, pix pixel) {
 		img.Pix[i+7] = uint8(a16 & 8)
 	case *image.Gray:
 		i := img.PixOffset(x, y)
-		img.Pix[i] = f32u8((0.299*pix.r + 0.587*pix.g + 0.114*pix.b) * pix.a * 0xff)
+		img.Pix[i] = f32u8((0.299*pix.R + 0.587*pix.G + 0.114*pix.B) * pix.A * 0xff)
 	case *image.Gray16:
 		i := img.PixOffset(x, y)
-		v := f32u16((0.299*pix.r + 0.587*pix.g + 0.114*pix.b) * pix.a * 0xffff)
+		v := f32u16((0.299*pix.R + 0.587*pix.G + 0.114*pix.B) * pix.A * 0xffff)
 		img.Pix[i] = uint8(v >> 8)
 		img.Pix[i+1] = uint8(v & 0xff)
 	default:
-		r := f32u16(pix.r * 0xffff)
-		g := f32u16(pix.g * 0xffff)
-		b := f32u16(pix.b * 0xffff)
-		a := f32u16(pix.a * 0xffff)
+		r := f32u16(pix.R * 0xffff)
+		g := f32u16(pix.G * 0xffff)
+		b := f32u16(pix.B * 0xffff)
+		a := f32u16(pix.A * 0xffff)
 		p.img.Set(x, y, color.NRGBA64{r, g, b, a})
 	}
 }
 
-func (p *pixelSetter) setPixelRow(y int, buf []pixel) {
-	for i, x := 0, p.bounds.Min.X; x < len(buf); i, x = i+1, x+1 {
+func (p *pixelSetter) setPixelRow(y int, buf []Pixel) {
+	for i, x := 0, p.bounds.Min.X; i < len(buf); i, x = i+1, x+1 {
 		p.setPixel(x, y, buf[i])
 	}
 }
 
-func (p *pixelSetter) setPixelColumn(x int, buf []pixel) {
-	for i, y := 0, p.bounds.Min.Y; y < len(buf); i, y = i+1, y+1 {
+func (p *pixelSetter) setPixelColumn(x int, buf []Pixel) {
+	for i, y := 0, p.bounds.Min.Y; i < len(buf); i, y = i+1, y+1 {
 		p.setPixel(x, y, buf[i])
 	}
 }
 
-func bilinearInterpolation(pixGetter *pixelGetter, x, y float32) pixel {
+func bilinearInterpolation(pixGetter *pixelGetter, x, y float32) Pixel {
 	xmin := int(gm32.Floor(x))
 	ymin := int(gm32.Floor(y))
 	xmax := xmin + 1
@@ -303,15 +467,15 @@ func bilinearInterpolation(pixGetter *pixelGetter, x, y float32) pixel {
 	fx := x - float32(xmin)
 	fy := y - float32(ymin)
 
-	r := gm32.InterpolateBilinear(p00.r, p01.r, p10.r, p11.r, fx, fy)
-	g := gm32.InterpolateBilinear(p00.g, p01.g, p10.g, p11.g, fx, fy)
-	b := gm32.InterpolateBilinear(p00.b, p01.b, p10.b, p11.b, fx, fy)
-	a := gm32.InterpolateBilinear(p00.a, p01.a, p10.a, p11.a, fx, fy)
+	r := gm32.InterpolateBilinear(p00.R, p01.R, p10.R, p11.R, fx, fy)
+	g := gm32.InterpolateBilinear(p00.G, p01.G, p10.G, p11.G, fx, fy)
+	b := gm32.InterpolateBilinear(p00.B, p01.B, p10.B, p11.B, fx, fy)
+	a := gm32.InterpolateBilinear(p00.A, p01.A, p10.A, p11.A, fx, fy)
 
-	return pixel{r, g, b, a}
+	return Pixel{r, g, b, a}
 }
 
-func bicubicInterpolation(pixGetter *pixelGetter, x, y, a float32) pixel {
+func bicubicInterpolation(pixGetter *pixelGetter, x, y, a float32) Pixel {
 	x1 := int(gm32.Floor(x))
 	x0 := x1 - 1
 	x2 := x1 + 1
@@ -361,31 +525,31 @@ func bicubicInterpolation(pixGetter *pixelGetter, x, y, a float32) pixel {
 	)
 
 	redMat := gm32.NewMat(4, 4)(
-		p00.r, p01.r, p02.r, p03.r,
-		p10.r, p11.r, p12.r, p13.r,
-		p20.r, p21.r, p22.r, p23.r,
-		p30.r, p31.r, p32.r, p33.r,
+		p00.R, p01.R, p02.R, p03.R,
+		p10.R, p11.R, p12.R, p13.R,
+		p20.R, p21.R, p22.R, p23.R,
+		p30.R, p31.R, p32.R, p33.R,
 	)
 
 	greenMat := gm32.NewMat(4, 4)(
-		p00.g, p01.g, p02.g, p03.g,
-		p10.g, p11.g, p12.g, p13.g,
-		p20.g, p21.g, p22.g, p23.g,
-		p30.g, p31.g, p32.g, p33.g,
+		p00.G, p01.G, p02.G, p03.G,
+		p10.G, p11.G, p12.G, p13.G,
+		p20.G, p21.G, p22.G, p23.G,
+		p30.G, p31.G, p32.G, p33.G,
 	)
 
 	blueMat := gm32.NewMat(4, 4)(
-		p00.b, p01.b, p02.b, p03.b,
-		p10.b, p11.b, p12.b, p13.b,
-		p20.b, p21.b, p22.b, p23.b,
-		p30.b, p31.b, p32.b, p33.b,
+		p00.B, p01.B, p02.B, p03.B,
+		p10.B, p11.B, p12.B, p13.B,
+		p20.B, p21.B, p22.B, p23.B,
+		p30.B, p31.B, p32.B, p33.B,
 	)
 
 	alphaMat := gm32.NewMat(4, 4)(
-		p00.a, p01.a, p02.a, p03.a,
-		p10.a, p11.a, p12.a, p13.a,
-		p20.a, p21.a, p22.a, p23.a,
-		p30.a, p31.a, p32.a, p33.a,
+		p00.A, p01.A, p02.A, p03.A,
+		p10.A, p11.A, p12.A, p13.A,
+		p20.A, p21.A, p22.A, p23.A,
+		p30.A, p31.A, p32.A, p33.A,
 	)
 
 	red := gm32.InterpolateBicubic(xMat, redMat, yMat, a)
@@ -393,11 +557,27 @@ func bicubicInterpolation(pixGetter *pixelGetter, x, y, a float32) pixel {
 	blue := gm32.InterpolateBicubic(xMat, blueMat, yMat, a)
 	alpha := gm32.InterpolateBicubic(xMat, alphaMat, yMat, a)
 
-	return pixel{red, green, blue, alpha}
+	return Pixel{red, green, blue, alpha}
 }
 
-func nearestNeighbor(pixGetter *pixelGetter, x, y float32) pixel {
+func nearestNeighbor(pixGetter *pixelGetter, x, y float32) Pixel {
 	xmin := int(gm32.Round(x))
 	ymin := int(gm32.Round(y))
 	return pixGetter.getPixel(xmin, ymin)
 }
+
+// Returns the pixel at the given position using the given interpolation method.
+// Pixel centers are at integer coordinates.
+// Unknown interpolation methods give transparent pixels.
+func interpolate(pixGetter *pixelGetter, x, y float32, interpolation Interpolation) Pixel {
+	switch interpolation {
+	case NearestNeighborInterpolation:
+		return nearestNeighbor(pixGetter, x, y)
+	case BilinearInterpolation:
+		return bilinearInterpolation(pixGetter, x, y)
+	case BicubicInterpolation:
+		return bicubicInterpolation(pixGetter, x, y, -0.75)
+	}
+
+	return Pixel{0, 0, 0, 0}
+}
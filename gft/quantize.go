@@ -0,0 +1,537 @@
+package gft
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"sort"
+
+	"github.com/infastin/gul/giu/gcu"
+	"github.com/infastin/gul/gm32"
+	"github.com/infastin/gul/tools"
+)
+
+// Color quantization method.
+type QuantMethod int
+
+const (
+	// Repeatedly splits the box of colors with the largest variance at the median.
+	MedianCutQuantization QuantMethod = iota
+	// Builds an octree of colors and merges its least populated leaves.
+	OctreeQuantization
+	// Refines the median cut palette with k-means clustering.
+	// It is slower, but gives the best results.
+	KMeansQuantization
+)
+
+// Can be combined with any method (e.g. KMeansQuantization | PerceptualQuantization)
+// to quantize colors in the perceptual Oklab color space instead of sRGB.
+const PerceptualQuantization QuantMethod = 1 << 8
+
+const (
+	maxPaletteSize    = 256
+	kmeansIterations  = 16
+	octreeDepth       = 8
+	octreeChildrenNum = 16
+)
+
+// Unique color of an image.
+type quantColor struct {
+	// Position in the color space with premultiplied alpha.
+	coord [4]float32
+	count float32
+}
+
+// Color space, where quantization takes place.
+type quantSpace struct {
+	perceptual bool
+}
+
+func (s quantSpace) coord(pix Pixel) [4]float32 {
+	c0, c1, c2 := pix.R, pix.G, pix.B
+	if s.perceptual {
+		c0, c1, c2 = gcu.RGBToOklab(c0, c1, c2)
+	}
+
+	return [4]float32{c0 * pix.A, c1 * pix.A, c2 * pix.A, pix.A}
+}
+
+func (s quantSpace) pixel(coord [4]float32) Pixel {
+	a := coord[3]
+	if a <= 0 {
+		return Pixel{0, 0, 0, 0}
+	}
+
+	c0, c1, c2 := coord[0]/a, coord[1]/a, coord[2]/a
+	if s.perceptual {
+		c0, c1, c2 = gcu.OklabToRGB(c0, c1, c2)
+	}
+
+	return Pixel{c0, c1, c2, a}.Clamp(0, 1)
+}
+
+// Returns the value of the coordinate in the range [0, 1].
+func (s quantSpace) normalize(i int, v float32) float32 {
+	// The a and b components of Oklab are approximately in the range [-0.5, 0.5].
+	if s.perceptual && (i == 1 || i == 2) {
+		v += 0.5
+	}
+
+	return gm32.Clamp(v, 0, 1)
+}
+
+func quantDistance(c0, c1 [4]float32) float32 {
+	var d float32
+	for i := range c0 {
+		diff := c0[i] - c1[i]
+		d += diff * diff
+	}
+
+	return d
+}
+
+func nearestCoord(coords [][4]float32, c [4]float32) int {
+	best, bestDist := 0, float32(-1)
+	for i := range coords {
+		d := quantDistance(coords[i], c)
+		if bestDist < 0 || d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+
+	return best
+}
+
+// Returns the key of an 8-bit non-premultiplied color.
+// All transparent colors have the same key.
+func quantKey(pix Pixel) uint32 {
+	if pix.A == 0 {
+		return 0
+	}
+
+	return uint32(f32u8(pix.R*0xff))<<24 | uint32(f32u8(pix.G*0xff))<<16 |
+		uint32(f32u8(pix.B*0xff))<<8 | uint32(f32u8(pix.A*0xff))
+}
+
+func quantKeyPixel(key uint32) Pixel {
+	return Pixel{
+		R: float32(key>>24) / 0xff,
+		G: float32(key>>16&0xff) / 0xff,
+		B: float32(key>>8&0xff) / 0xff,
+		A: float32(key&0xff) / 0xff,
+	}
+}
+
+// Collects unique 8-bit colors of an image.
+// Returns the colors and the map, whose keys are the keys of the colors.
+func collectColors(img image.Image, space quantSpace) ([]quantColor, map[uint32]int) {
+	bounds := img.Bounds()
+	pixGetter := newPixelGetter(img)
+
+	counts := make(map[uint32]int)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			counts[quantKey(pixGetter.getPixel(x, y))]++
+		}
+	}
+
+	// Map iteration order is random, but the result must be deterministic.
+	keys := make([]uint32, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	colors := make([]quantColor, len(keys))
+	for i, key := range keys {
+		colors[i] = quantColor{
+			coord: space.coord(quantKeyPixel(key)),
+			count: float32(counts[key]),
+		}
+	}
+
+	return colors, counts
+}
+
+func meanCoord(colors []quantColor) [4]float32 {
+	var sum [4]float32
+	var count float32
+
+	for _, c := range colors {
+		for i := range sum {
+			sum[i] += c.coord[i] * c.count
+		}
+		count += c.count
+	}
+
+	for i := range sum {
+		sum[i] /= count
+	}
+
+	return sum
+}
+
+type medianCutBox struct {
+	colors []quantColor
+	// Weighted sum of squared errors.
+	sse  float32
+	axis int
+}
+
+func newMedianCutBox(colors []quantColor) medianCutBox {
+	mean := meanCoord(colors)
+
+	var variance [4]float32
+	for _, c := range colors {
+		for i := range variance {
+			d := c.coord[i] - mean[i]
+			variance[i] += d * d * c.count
+		}
+	}
+
+	box := medianCutBox{colors: colors}
+	for i, v := range variance {
+		box.sse += v
+		if v > variance[box.axis] {
+			box.axis = i
+		}
+	}
+
+	return box
+}
+
+// Splits the box along its axis with the largest variance at the weighted median.
+func (b medianCutBox) split() (medianCutBox, medianCutBox) {
+	axis := b.axis
+	sort.SliceStable(b.colors, func(i, j int) bool {
+		return b.colors[i].coord[axis] < b.colors[j].coord[axis]
+	})
+
+	var total float32
+	for _, c := range b.colors {
+		total += c.count
+	}
+
+	var acc float32
+	median := 1
+	for i, c := range b.colors[:len(b.colors)-1] {
+		acc += c.count
+		median = i + 1
+		if acc >= total/2 {
+			break
+		}
+	}
+
+	return newMedianCutBox(b.colors[:median]), newMedianCutBox(b.colors[median:])
+}
+
+func medianCut(colors []quantColor, n int) [][4]float32 {
+	boxes := []medianCutBox{newMedianCutBox(colors)}
+
+	for len(boxes) < n {
+		best := -1
+		for i, box := range boxes {
+			if len(box.colors) > 1 && box.sse > 0 && (best < 0 || box.sse > boxes[best].sse) {
+				best = i
+			}
+		}
+
+		if best < 0 {
+			break
+		}
+
+		b0, b1 := boxes[best].split()
+		boxes[best] = b0
+		boxes = append(boxes, b1)
+	}
+
+	coords := make([][4]float32, len(boxes))
+	for i, box := range boxes {
+		coords[i] = meanCoord(box.colors)
+	}
+
+	return coords
+}
+
+type octreeNode struct {
+	children [octreeChildrenNum]*octreeNode
+	sum      [4]float32
+	count    float32
+	leaf     bool
+}
+
+func octree(colors []quantColor, n int, space quantSpace) [][4]float32 {
+	root := &octreeNode{}
+	leaves := 0
+
+	// Internal nodes at each depth.
+	levels := make([][]*octreeNode, octreeDepth)
+	levels[0] = []*octreeNode{root}
+
+	for _, c := range colors {
+		var bytes [4]uint8
+		for i, v := range c.coord {
+			bytes[i] = f32u8(space.normalize(i, v) * 0xff)
+		}
+
+		node := root
+		for level := 0; ; level++ {
+			for i := range node.sum {
+				node.sum[i] += c.coord[i] * c.count
+			}
+			node.count += c.count
+
+			if level == octreeDepth {
+				if !node.leaf {
+					node.leaf = true
+					leaves++
+				}
+				break
+			}
+
+			idx := 0
+			shift := octreeDepth - 1 - level
+			for i, b := range bytes {
+				idx |= int(b>>shift&1) << i
+			}
+
+			if node.children[idx] == nil {
+				node.children[idx] = &octreeNode{}
+				if level+1 < octreeDepth {
+					levels[level+1] = append(levels[level+1], node.children[idx])
+				}
+			}
+
+			node = node.children[idx]
+		}
+	}
+
+	// Merges the least populated nodes of the deepest levels first,
+	// so children of a node are always leaves, when it is merged.
+	for level := octreeDepth - 1; level >= 0 && leaves > n; level-- {
+		nodes := levels[level]
+		sort.SliceStable(nodes, func(i, j int) bool {
+			return nodes[i].count < nodes[j].count
+		})
+
+		for _, node := range nodes {
+			if leaves <= n {
+				break
+			}
+
+			merged := 0
+			for i, child := range node.children {
+				if child != nil {
+					merged++
+					node.children[i] = nil
+				}
+			}
+
+			node.leaf = true
+			leaves -= merged - 1
+		}
+	}
+
+	coords := make([][4]float32, 0, leaves)
+
+	var collect func(node *octreeNode)
+	collect = func(node *octreeNode) {
+		if node.leaf {
+			var c [4]float32
+			for i := range c {
+				c[i] = node.sum[i] / node.count
+			}
+			coords = append(coords, c)
+			return
+		}
+
+		for _, child := range node.children {
+			if child != nil {
+				collect(child)
+			}
+		}
+	}
+	collect(root)
+
+	return coords
+}
+
+func kmeans(colors []quantColor, n int) [][4]float32 {
+	coords := medianCut(colors, n)
+	assignment := make([]int, len(colors))
+	for i := range assignment {
+		assignment[i] = -1
+	}
+
+	for iter := 0; iter < kmeansIterations; iter++ {
+		changed := false
+		for i, c := range colors {
+			nearest := nearestCoord(coords, c.coord)
+			if nearest != assignment[i] {
+				assignment[i] = nearest
+				changed = true
+			}
+		}
+
+		if !changed {
+			break
+		}
+
+		sums := make([][4]float32, len(coords))
+		counts := make([]float32, len(coords))
+
+		for i, c := range colors {
+			k := assignment[i]
+			for j := range c.coord {
+				sums[k][j] += c.coord[j] * c.count
+			}
+			counts[k] += c.count
+		}
+
+		// Empty clusters keep their centroids.
+		for k := range coords {
+			if counts[k] == 0 {
+				continue
+			}
+
+			for j := range coords[k] {
+				coords[k][j] = sums[k][j] / counts[k]
+			}
+		}
+	}
+
+	return coords
+}
+
+func quantize(img image.Image, n int, method QuantMethod) (color.Palette, [][4]float32, quantSpace, map[uint32]int) {
+	space := quantSpace{perceptual: method&PerceptualQuantization != 0}
+	method &^= PerceptualQuantization
+
+	if n > maxPaletteSize {
+		n = maxPaletteSize
+	}
+
+	colors, indices := collectColors(img, space)
+	if len(colors) == 0 {
+		return nil, nil, space, nil
+	}
+
+	// Transparent pixels get their own palette color, so that they stay transparent.
+	// The transparent color has the smallest key, so it is the first one.
+	var coords [][4]float32
+	if _, ok := indices[0]; ok && n > 1 && len(colors) > 1 {
+		coords = append(coords, colors[0].coord)
+		colors = colors[1:]
+		n--
+	}
+
+	switch method {
+	case OctreeQuantization:
+		coords = append(coords, octree(colors, n, space)...)
+	case KMeansQuantization:
+		coords = append(coords, kmeans(colors, n)...)
+	default:
+		coords = append(coords, medianCut(colors, n)...)
+	}
+
+	palette := make(color.Palette, len(coords))
+	for i, c := range coords {
+		pix := space.pixel(c)
+		palette[i] = color.NRGBA{
+			R: f32u8(pix.R * 0xff),
+			G: f32u8(pix.G * 0xff),
+			B: f32u8(pix.B * 0xff),
+			A: f32u8(pix.A * 0xff),
+		}
+	}
+
+	return palette, coords, space, indices
+}
+
+// Builds a palette of at most n colors (no more than 256), which represents the colors of an image,
+// using given quantization method.
+// If the image has transparent pixels, one of the palette colors is transparent.
+// Returns nil, if n <= 0 or the image is empty.
+func Quantize(img image.Image, n int, method QuantMethod) color.Palette {
+	if n <= 0 {
+		return nil
+	}
+
+	palette, _, _, _ := quantize(img, n, method)
+	return palette
+}
+
+type quantizeFilter struct {
+	n      int
+	method QuantMethod
+}
+
+func (f *quantizeFilter) Bounds(src image.Rectangle) image.Rectangle {
+	return image.Rect(0, 0, src.Dx(), src.Dy())
+}
+
+func (f *quantizeFilter) Apply(dst draw.Image, src image.Image, parallel bool) {
+	palette, coords, space, indices := quantize(src, f.n, f.method)
+	if palette == nil {
+		return
+	}
+
+	// Each unique color is mapped to the nearest palette color once.
+	for key := range indices {
+		indices[key] = nearestCoord(coords, space.coord(quantKeyPixel(key)))
+	}
+
+	srcb := src.Bounds()
+	dstb := dst.Bounds()
+
+	paletted, isPaletted := dst.(*image.Paletted)
+	if isPaletted {
+		paletted.Palette = palette
+	}
+
+	pixels := make([]Pixel, len(palette))
+	for i, c := range palette {
+		pixels[i] = pixelFromColor(c)
+	}
+
+	pixGetter := newPixelGetter(src)
+	pixSetter := newPixelSetter(dst)
+
+	procs := 1
+	if parallel {
+		procs = 0
+	}
+
+	tools.Parallelize(procs, srcb.Min.Y, srcb.Max.Y, 1, func(start, end int) {
+		for y := start; y < end; y++ {
+			for x := srcb.Min.X; x < srcb.Max.X; x++ {
+				idx := indices[quantKey(pixGetter.getPixel(x, y))]
+				dstX := dstb.Min.X + x - srcb.Min.X
+				dstY := dstb.Min.Y + y - srcb.Min.Y
+
+				if isPaletted {
+					paletted.SetColorIndex(dstX, dstY, uint8(idx))
+				} else {
+					pixSetter.setPixel(dstX, dstY, pixels[idx])
+				}
+			}
+		}
+	})
+}
+
+// Reduces the number of colors of an image to at most n (no more than 256)
+// using given quantization method. See Quantize.
+//
+// If the dst image is *image.Paletted, its palette is replaced with the quantized palette
+// and pixels are set to palette indices, so the result can be encoded to GIF or paletted PNG.
+// Returns nil, if n <= 0.
+func QuantizeColors(n int, method QuantMethod) Filter {
+	if n <= 0 {
+		return nil
+	}
+
+	return &quantizeFilter{
+		n:      n,
+		method: method,
+	}
+}
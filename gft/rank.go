@@ -0,0 +1,229 @@
+package gft
+
+import (
+	"image"
+	"image/draw"
+	"sort"
+
+	"github.com/infastin/gul/gm32"
+	"github.com/infastin/gul/gmu"
+	"github.com/infastin/gul/tools"
+)
+
+type rankFilter struct {
+	radius int
+	rank   float32
+}
+
+func (f *rankFilter) Bounds(src image.Rectangle) image.Rectangle {
+	return src
+}
+
+func (f *rankFilter) Apply(dst draw.Image, src image.Image, parallel bool) {
+	switch img := src.(type) {
+	case *image.RGBA:
+		f.apply8(dst, img.Rect, img.Pix, img.Stride, 4, parallel, func(v [4]uint8) Pixel {
+			if v[3] == 0 {
+				return Pixel{0, 0, 0, 0}
+			}
+
+			a := v[3]
+			q := float32(1) / float32(a)
+			return Pixel{
+				R: float32(gmu.MinInt(int(v[0]), int(a))) * q,
+				G: float32(gmu.MinInt(int(v[1]), int(a))) * q,
+				B: float32(gmu.MinInt(int(v[2]), int(a))) * q,
+				A: float32(a) * qf8,
+			}
+		})
+	case *image.NRGBA:
+		f.apply8(dst, img.Rect, img.Pix, img.Stride, 4, parallel, func(v [4]uint8) Pixel {
+			return Pixel{
+				R: float32(v[0]) * qf8,
+				G: float32(v[1]) * qf8,
+				B: float32(v[2]) * qf8,
+				A: float32(v[3]) * qf8,
+			}
+		})
+	case *image.Gray:
+		f.apply8(dst, img.Rect, img.Pix, img.Stride, 1, parallel, func(v [4]uint8) Pixel {
+			g := float32(v[0]) * qf8
+			return Pixel{g, g, g, 1}
+		})
+	default:
+		f.applyGeneric(dst, src, parallel)
+	}
+}
+
+// Applies the filter to an image with 8-bit channels.
+// Uses a sliding window histogram, so the complexity doesn't depend much on the radius.
+func (f *rankFilter) apply8(
+	dst draw.Image, srcb image.Rectangle, pix []uint8, stride, numChans int,
+	parallel bool, toPixel func(v [4]uint8) Pixel,
+) {
+	dstb := dst.Bounds()
+	pixSetter := newPixelSetter(dst)
+
+	procs := 1
+	if parallel {
+		procs = 0
+	}
+
+	tools.Parallelize(procs, srcb.Min.Y, srcb.Max.Y, 1, func(start, end int) {
+		var hist [4][256]int
+
+		for y := start; y < end; y++ {
+			for c := 0; c < numChans; c++ {
+				hist[c] = [256]int{}
+			}
+
+			ymin := gmu.MaxInt(y-f.radius, srcb.Min.Y)
+			ymax := gmu.MinInt(y+f.radius, srcb.Max.Y-1)
+			count := 0
+
+			updateColumn := func(x, delta int) {
+				for yi := ymin; yi <= ymax; yi++ {
+					i := (yi-srcb.Min.Y)*stride + (x-srcb.Min.X)*numChans
+					for c := 0; c < numChans; c++ {
+						hist[c][pix[i+c]] += delta
+					}
+				}
+
+				count += delta * (ymax - ymin + 1)
+			}
+
+			for x := srcb.Min.X; x <= gmu.MinInt(srcb.Min.X+f.radius, srcb.Max.X-1); x++ {
+				updateColumn(x, 1)
+			}
+
+			for x := srcb.Min.X; x < srcb.Max.X; x++ {
+				k := int(gm32.Round(f.rank * float32(count-1)))
+
+				var v [4]uint8
+				for c := 0; c < numChans; c++ {
+					sum := 0
+					for j := 0; j < 256; j++ {
+						sum += hist[c][j]
+						if sum > k {
+							v[c] = uint8(j)
+							break
+						}
+					}
+				}
+
+				if numChans == 1 {
+					v[1], v[2], v[3] = v[0], v[0], 0xff
+				}
+
+				pixSetter.setPixel(dstb.Min.X+x-srcb.Min.X, dstb.Min.Y+y-srcb.Min.Y, toPixel(v))
+
+				if x-f.radius >= srcb.Min.X {
+					updateColumn(x-f.radius, -1)
+				}
+
+				if x+f.radius+1 < srcb.Max.X {
+					updateColumn(x+f.radius+1, 1)
+				}
+			}
+		}
+	})
+}
+
+func (f *rankFilter) applyGeneric(dst draw.Image, src image.Image, parallel bool) {
+	srcb := src.Bounds()
+	dstb := dst.Bounds()
+
+	pixGetter := newPixelGetter(src)
+	pixSetter := newPixelSetter(dst)
+
+	procs := 1
+	if parallel {
+		procs = 0
+	}
+
+	tools.Parallelize(procs, srcb.Min.Y, srcb.Max.Y, 1, func(start, end int) {
+		size := (2*f.radius + 1) * (2*f.radius + 1)
+		var chans [4][]float32
+		for c := range chans {
+			chans[c] = make([]float32, 0, size)
+		}
+
+		for y := start; y < end; y++ {
+			ymin := gmu.MaxInt(y-f.radius, srcb.Min.Y)
+			ymax := gmu.MinInt(y+f.radius, srcb.Max.Y-1)
+
+			for x := srcb.Min.X; x < srcb.Max.X; x++ {
+				xmin := gmu.MaxInt(x-f.radius, srcb.Min.X)
+				xmax := gmu.MinInt(x+f.radius, srcb.Max.X-1)
+
+				for c := range chans {
+					chans[c] = chans[c][:0]
+				}
+
+				for yi := ymin; yi <= ymax; yi++ {
+					for xi := xmin; xi <= xmax; xi++ {
+						pix := pixGetter.getPixel(xi, yi)
+						chans[0] = append(chans[0], pix.R*pix.A)
+						chans[1] = append(chans[1], pix.G*pix.A)
+						chans[2] = append(chans[2], pix.B*pix.A)
+						chans[3] = append(chans[3], pix.A)
+					}
+				}
+
+				k := int(gm32.Round(f.rank * float32(len(chans[0])-1)))
+
+				var v [4]float32
+				for c := range chans {
+					vals := chans[c]
+					sort.Slice(vals, func(i, j int) bool {
+						return vals[i] < vals[j]
+					})
+					v[c] = vals[k]
+				}
+
+				var pix Pixel
+				if v[3] > 0 {
+					q := 1 / v[3]
+					pix = Pixel{v[0] * q, v[1] * q, v[2] * q, v[3]}
+				}
+
+				pixSetter.setPixel(dstb.Min.X+x-srcb.Min.X, dstb.Min.Y+y-srcb.Min.Y, pix.Clamp(0, 1))
+			}
+		}
+	})
+}
+
+// Replaces each pixel with the value at the given percentile of its square neighborhood.
+// Each channel is processed independently.
+// The radius parameter must be positive.
+// The percentile parameter must be in the range [0, 100].
+// The filter is much faster for *image.RGBA, *image.NRGBA and *image.Gray images.
+func Percentile(radius int, perc float32) Filter {
+	if radius <= 0 {
+		return nil
+	}
+
+	return &rankFilter{
+		radius: radius,
+		rank:   gm32.Clamp(perc, 0, 100) / 100,
+	}
+}
+
+// Replaces each pixel with the median of its square neighborhood.
+// Removes salt-and-pepper noise while preserving edges.
+// The radius parameter must be positive.
+func Median(radius int) Filter {
+	return Percentile(radius, 50)
+}
+
+// Replaces each pixel with the minimum of its square neighborhood.
+// The radius parameter must be positive.
+func Minimum(radius int) Filter {
+	return Percentile(radius, 0)
+}
+
+// Replaces each pixel with the maximum of its square neighborhood.
+// The radius parameter must be positive.
+func Maximum(radius int) Filter {
+	return Percentile(radius, 100)
+}
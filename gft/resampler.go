@@ -119,18 +119,18 @@ func (r *resampler) makeCList(dst, src segment, filtScale float32) [][]contrib {
 	return result
 }
 
-func (res *resampler) resampleSegment(dst []pixel, src []pixel, clist [][]contrib) {
+func (res *resampler) resampleSegment(dst []Pixel, src []Pixel, clist [][]contrib) {
 	for i := 0; i < len(dst); i++ {
 		var r, g, b, a float32
 		for _, c := range clist[i] {
 			col := src[c.index]
-			r += col.r * c.weight
-			g += col.g * c.weight
-			b += col.b * c.weight
-			a += col.a * c.weight
+			r += col.R * c.weight
+			g += col.G * c.weight
+			b += col.B * c.weight
+			a += col.A * c.weight
 		}
 
-		dst[i] = pixel{r, g, b, a}
+		dst[i] = Pixel{r, g, b, a}
 	}
 }
 
@@ -153,8 +153,8 @@ func (r *resampler) resampleX(dst draw.Image, src image.Image, parallel bool) {
 	}
 
 	tools.Parallelize(procs, srcb.Min.Y, srcb.Max.Y, 1, func(start, end int) {
-		srcBuf := make([]pixel, srcb.Dx())
-		dstBuf := make([]pixel, dstb.Dx())
+		srcBuf := make([]Pixel, srcb.Dx())
+		dstBuf := make([]Pixel, dstb.Dx())
 
 		for y := start; y < end; y++ {
 			pixGetter.getPixelRow(y, &srcBuf)
@@ -183,8 +183,8 @@ func (r *resampler) resampleY(dst draw.Image, src image.Image, parallel bool) {
 	}
 
 	tools.Parallelize(procs, srcb.Min.X, srcb.Max.X, 1, func(start, end int) {
-		srcBuf := make([]pixel, srcb.Dy())
-		dstBuf := make([]pixel, dstb.Dy())
+		srcBuf := make([]Pixel, srcb.Dy())
+		dstBuf := make([]Pixel, dstb.Dy())
 
 		for x := start; x < end; x++ {
 			pixGetter.getPixelColumn(x, &srcBuf)
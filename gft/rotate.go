@@ -93,18 +93,7 @@ func (f *rotateFilter) Apply(dst draw.Image, src image.Image, parallel bool) {
 				x2 := cosine*x - sine*y + halfSrcWidth
 				y2 := sine*x + cosine*y + halfSrcHeight
 
-				var rgba pixel
-
-				switch f.interpolation {
-				default:
-				case NearestNeighborInterpolation:
-					rgba = nearestNeighbor(pixGetter, x2, y2)
-				case BilinearInterpolation:
-					rgba = bilinearInterpolation(pixGetter, x2, y2)
-				case BicubicInterpolation:
-					rgba = bicubicInterpolation(pixGetter, x2, y2, -0.75)
-				}
-
+				rgba := interpolate(pixGetter, x2, y2, f.interpolation)
 				pixSetter.setPixel(xi, yi, rgba)
 			}
 		}
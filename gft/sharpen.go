@@ -0,0 +1,186 @@
+package gft
+
+import (
+	"image"
+	"image/draw"
+
+	"github.com/infastin/gul/gm32"
+	"github.com/infastin/gul/tools"
+)
+
+// Returns a blurred copy of an image.
+func gaussianBlurred(src image.Image, sigma float32, parallel bool) *pixelImage {
+	blurred := newPixelImage(src.Bounds())
+	kernel := gaussianKernel(sigma)
+	convolveSeparable(blurred, src, kernel, kernel, parallel)
+	return blurred
+}
+
+type unsharpMaskFilter struct {
+	sigma      float32
+	amount     float32
+	threshold  float32
+	mergeCount uint
+}
+
+func (f *unsharpMaskFilter) Bounds(src image.Rectangle) image.Rectangle {
+	return src
+}
+
+func (f *unsharpMaskFilter) sharpen(orig, blurred float32) float32 {
+	diff := orig - blurred
+	if gm32.Abs(diff) < f.threshold {
+		return orig
+	}
+
+	return gm32.Clamp(orig+diff*f.amount, 0, 1)
+}
+
+func (f *unsharpMaskFilter) Apply(dst draw.Image, src image.Image, parallel bool) {
+	srcb := src.Bounds()
+	dstb := dst.Bounds()
+
+	blurred := gaussianBlurred(src, f.sigma, parallel)
+
+	pixGetter := newPixelGetter(src)
+	blurGetter := newPixelGetter(blurred)
+	pixSetter := newPixelSetter(dst)
+
+	procs := 1
+	if parallel {
+		procs = 0
+	}
+
+	tools.Parallelize(procs, srcb.Min.Y, srcb.Max.Y, 1, func(start, end int) {
+		for y := start; y < end; y++ {
+			for x := srcb.Min.X; x < srcb.Max.X; x++ {
+				pix := pixGetter.getPixel(x, y)
+				blur := blurGetter.getPixel(x, y)
+
+				pix.R = f.sharpen(pix.R, blur.R)
+				pix.G = f.sharpen(pix.G, blur.G)
+				pix.B = f.sharpen(pix.B, blur.B)
+
+				pixSetter.setPixel(dstb.Min.X+x-srcb.Min.X, dstb.Min.Y+y-srcb.Min.Y, pix)
+			}
+		}
+	})
+}
+
+func (f *unsharpMaskFilter) CanMerge(filter Filter) bool {
+	if filt, ok := filter.(*unsharpMaskFilter); ok {
+		return f.sigma == filt.sigma && f.threshold == filt.threshold
+	}
+
+	return false
+}
+
+func (f *unsharpMaskFilter) Merge(filter Filter) {
+	filt := filter.(*unsharpMaskFilter)
+
+	f.amount += filt.amount
+	f.mergeCount++
+}
+
+func (f *unsharpMaskFilter) CanUndo(filter Filter) bool {
+	if filt, ok := filter.(*unsharpMaskFilter); ok {
+		return f.sigma == filt.sigma && f.threshold == filt.threshold
+	}
+
+	return false
+}
+
+func (f *unsharpMaskFilter) Undo(filter Filter) bool {
+	filt := filter.(*unsharpMaskFilter)
+
+	f.amount -= filt.amount
+	f.mergeCount--
+
+	return f.mergeCount == 0
+}
+
+func (f *unsharpMaskFilter) Skip() bool {
+	return f.amount == 0
+}
+
+func (f *unsharpMaskFilter) Copy() Filter {
+	return &unsharpMaskFilter{
+		sigma:      f.sigma,
+		amount:     f.amount,
+		threshold:  f.threshold,
+		mergeCount: f.mergeCount,
+	}
+}
+
+// Sharpens an image by subtracting its blurred version.
+// The sigma parameter must be positive and specifies the blur radius.
+// The amount parameter specifies the strength of the sharpening (typically in the range [0.5, 1.5]).
+// The threshold parameter specifies the minimal difference between the original and blurred colors
+// that will be sharpened (typically in the range [0, 0.05]).
+//
+// Filters with the same sigma and threshold can be merged, their amounts are summed.
+func UnsharpMask(sigma, amount, threshold float32) MergingFilter {
+	if sigma <= 0 || amount == 0 {
+		return nil
+	}
+
+	return &unsharpMaskFilter{
+		sigma:      sigma,
+		amount:     amount,
+		threshold:  gm32.Max(0, threshold),
+		mergeCount: 1,
+	}
+}
+
+type highPassFilter struct {
+	radius float32
+}
+
+func (f *highPassFilter) Bounds(src image.Rectangle) image.Rectangle {
+	return src
+}
+
+func (f *highPassFilter) Apply(dst draw.Image, src image.Image, parallel bool) {
+	srcb := src.Bounds()
+	dstb := dst.Bounds()
+
+	blurred := gaussianBlurred(src, f.radius, parallel)
+
+	pixGetter := newPixelGetter(src)
+	blurGetter := newPixelGetter(blurred)
+	pixSetter := newPixelSetter(dst)
+
+	procs := 1
+	if parallel {
+		procs = 0
+	}
+
+	tools.Parallelize(procs, srcb.Min.Y, srcb.Max.Y, 1, func(start, end int) {
+		for y := start; y < end; y++ {
+			for x := srcb.Min.X; x < srcb.Max.X; x++ {
+				pix := pixGetter.getPixel(x, y)
+				blur := blurGetter.getPixel(x, y)
+
+				pix.R = gm32.Clamp(pix.R-blur.R+0.5, 0, 1)
+				pix.G = gm32.Clamp(pix.G-blur.G+0.5, 0, 1)
+				pix.B = gm32.Clamp(pix.B-blur.B+0.5, 0, 1)
+
+				pixSetter.setPixel(dstb.Min.X+x-srcb.Min.X, dstb.Min.Y+y-srcb.Min.Y, pix)
+			}
+		}
+	})
+}
+
+// Keeps only the fine details of an image, turning flat areas to middle gray.
+// The result is usually blended with the original image using overlay or soft light to sharpen it.
+// The radius parameter must be positive and specifies the standard deviation of the Gaussian blur
+// subtracted from the image.
+func HighPass(radius float32) Filter {
+	if radius <= 0 {
+		return nil
+	}
+
+	return &highPassFilter{
+		radius: radius,
+	}
+}
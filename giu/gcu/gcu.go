@@ -24,6 +24,55 @@ func RGBLuminance(r, g, b float32) float32 {
 	return r*lr + g*lg + b*lb
 }
 
+func SRGBToLinear(v float32) float32 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+
+	return gm32.Pow((v+0.055)/1.055, 2.4)
+}
+
+func LinearToSRGB(v float32) float32 {
+	if v <= 0.0031308 {
+		return v * 12.92
+	}
+
+	return 1.055*gm32.Pow(v, 1/2.4) - 0.055
+}
+
+// Converts sRGB to the perceptual Oklab color space (https://bottosson.github.io/posts/oklab/).
+func RGBToOklab(r, g, b float32) (l, a, bb float32) {
+	r, g, b = SRGBToLinear(r), SRGBToLinear(g), SRGBToLinear(b)
+
+	lc := gm32.Cbrt(0.4122214708*r + 0.5363325363*g + 0.0514459929*b)
+	mc := gm32.Cbrt(0.2119034982*r + 0.6806995451*g + 0.1073969566*b)
+	sc := gm32.Cbrt(0.0883024619*r + 0.2817188376*g + 0.6299787005*b)
+
+	l = 0.2104542553*lc + 0.7936177850*mc - 0.0040720468*sc
+	a = 1.9779984951*lc - 2.4285922050*mc + 0.4505937099*sc
+	bb = 0.0259040371*lc + 0.7827717662*mc - 0.8086757660*sc
+
+	return
+}
+
+func OklabToRGB(l, a, bb float32) (r, g, b float32) {
+	lc := l + 0.3963377774*a + 0.2158037573*bb
+	mc := l - 0.1055613458*a - 0.0638541728*bb
+	sc := l - 0.0894841775*a - 1.2914855480*bb
+
+	lc, mc, sc = lc*lc*lc, mc*mc*mc, sc*sc*sc
+
+	r = 4.0767416621*lc - 3.3077115913*mc + 0.2309699292*sc
+	g = -1.2684380046*lc + 2.6097574011*mc - 0.3413193965*sc
+	b = -0.0041960863*lc - 0.7034186147*mc + 1.7076147010*sc
+
+	r = LinearToSRGB(gm32.Clamp(r, 0, 1))
+	g = LinearToSRGB(gm32.Clamp(g, 0, 1))
+	b = LinearToSRGB(gm32.Clamp(b, 0, 1))
+
+	return
+}
+
 func RGBToHSL(r, g, b float32) (h, s, l float32) {
 	min := gm32.Min(r, gm32.Min(g, b))
 	max := gm32.Max(r, gm32.Max(g, b))
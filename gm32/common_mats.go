@@ -66,6 +66,66 @@ func (m Mat2) Det() float32 {
 	return m[0]*m[3] - m[1]*m[2]
 }
 
+// Returns the inverse of the matrix.
+// If the matrix is singular, returns false.
+func (m Mat2) Inverse() (Mat2, bool) {
+	var inv Mat2
+	for i := 0; i < 2; i++ {
+		inv[i*2+i] = 1
+	}
+
+	// Gauss-Jordan elimination with partial pivoting.
+	for col := 0; col < 2; col++ {
+		pivot := col
+		pivotAbs := m[col*2+col]
+		if pivotAbs < 0 {
+			pivotAbs = -pivotAbs
+		}
+
+		for i := col + 1; i < 2; i++ {
+			v := m[i*2+col]
+			if v < 0 {
+				v = -v
+			}
+
+			if v > pivotAbs {
+				pivot, pivotAbs = i, v
+			}
+		}
+
+		if pivotAbs == 0 {
+			return Mat2{}, false
+		}
+
+		if pivot != col {
+			for j := 0; j < 2; j++ {
+				m[col*2+j], m[pivot*2+j] = m[pivot*2+j], m[col*2+j]
+				inv[col*2+j], inv[pivot*2+j] = inv[pivot*2+j], inv[col*2+j]
+			}
+		}
+
+		q := 1 / m[col*2+col]
+		for j := 0; j < 2; j++ {
+			m[col*2+j] *= q
+			inv[col*2+j] *= q
+		}
+
+		for i := 0; i < 2; i++ {
+			if i == col {
+				continue
+			}
+
+			f := m[i*2+col]
+			for j := 0; j < 2; j++ {
+				m[i*2+j] -= f * m[col*2+j]
+				inv[i*2+j] -= f * inv[col*2+j]
+			}
+		}
+	}
+
+	return inv, true
+}
+
 func (m Mat2) Transpose() Mat2 {
 	return Mat2{
 		m[0], m[2],
@@ -668,6 +728,66 @@ func (m Mat3) Det() float32 {
 	return m[0]*m[4]*m[8] - m[0]*m[5]*m[7] - m[1]*m[3]*m[8] + m[1]*m[5]*m[6] + m[2]*m[3]*m[7] - m[2]*m[4]*m[6]
 }
 
+// Returns the inverse of the matrix.
+// If the matrix is singular, returns false.
+func (m Mat3) Inverse() (Mat3, bool) {
+	var inv Mat3
+	for i := 0; i < 3; i++ {
+		inv[i*3+i] = 1
+	}
+
+	// Gauss-Jordan elimination with partial pivoting.
+	for col := 0; col < 3; col++ {
+		pivot := col
+		pivotAbs := m[col*3+col]
+		if pivotAbs < 0 {
+			pivotAbs = -pivotAbs
+		}
+
+		for i := col + 1; i < 3; i++ {
+			v := m[i*3+col]
+			if v < 0 {
+				v = -v
+			}
+
+			if v > pivotAbs {
+				pivot, pivotAbs = i, v
+			}
+		}
+
+		if pivotAbs == 0 {
+			return Mat3{}, false
+		}
+
+		if pivot != col {
+			for j := 0; j < 3; j++ {
+				m[col*3+j], m[pivot*3+j] = m[pivot*3+j], m[col*3+j]
+				inv[col*3+j], inv[pivot*3+j] = inv[pivot*3+j], inv[col*3+j]
+			}
+		}
+
+		q := 1 / m[col*3+col]
+		for j := 0; j < 3; j++ {
+			m[col*3+j] *= q
+			inv[col*3+j] *= q
+		}
+
+		for i := 0; i < 3; i++ {
+			if i == col {
+				continue
+			}
+
+			f := m[i*3+col]
+			for j := 0; j < 3; j++ {
+				m[i*3+j] -= f * m[col*3+j]
+				inv[i*3+j] -= f * inv[col*3+j]
+			}
+		}
+	}
+
+	return inv, true
+}
+
 func (m Mat3) Transpose() Mat3 {
 	return Mat3{
 		m[0], m[3], m[6],
@@ -1316,6 +1436,66 @@ func (m Mat4) Det() float32 {
 		m[3]*m[5]*m[8]*m[14] - m[3]*m[5]*m[10]*m[12] - m[3]*m[6]*m[8]*m[13] + m[3]*m[6]*m[9]*m[12]
 }
 
+// Returns the inverse of the matrix.
+// If the matrix is singular, returns false.
+func (m Mat4) Inverse() (Mat4, bool) {
+	var inv Mat4
+	for i := 0; i < 4; i++ {
+		inv[i*4+i] = 1
+	}
+
+	// Gauss-Jordan elimination with partial pivoting.
+	for col := 0; col < 4; col++ {
+		pivot := col
+		pivotAbs := m[col*4+col]
+		if pivotAbs < 0 {
+			pivotAbs = -pivotAbs
+		}
+
+		for i := col + 1; i < 4; i++ {
+			v := m[i*4+col]
+			if v < 0 {
+				v = -v
+			}
+
+			if v > pivotAbs {
+				pivot, pivotAbs = i, v
+			}
+		}
+
+		if pivotAbs == 0 {
+			return Mat4{}, false
+		}
+
+		if pivot != col {
+			for j := 0; j < 4; j++ {
+				m[col*4+j], m[pivot*4+j] = m[pivot*4+j], m[col*4+j]
+				inv[col*4+j], inv[pivot*4+j] = inv[pivot*4+j], inv[col*4+j]
+			}
+		}
+
+		q := 1 / m[col*4+col]
+		for j := 0; j < 4; j++ {
+			m[col*4+j] *= q
+			inv[col*4+j] *= q
+		}
+
+		for i := 0; i < 4; i++ {
+			if i == col {
+				continue
+			}
+
+			f := m[i*4+col]
+			for j := 0; j < 4; j++ {
+				m[i*4+j] -= f * m[col*4+j]
+				inv[i*4+j] -= f * inv[col*4+j]
+			}
+		}
+	}
+
+	return inv, true
+}
+
 func (m Mat4) Transpose() Mat4 {
 	return Mat4{
 		m[0], m[4], m[8], m[12],
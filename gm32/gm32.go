@@ -25,6 +25,11 @@ func Tan(x float32) float32 {
 	return float32(tan)
 }
 
+func Atan2(y, x float32) float32 {
+	atan := math.Atan2(float64(y), float64(x))
+	return float32(atan)
+}
+
 func Sinc(x float32) float32 {
 	f := gm64.Sinc(float64(x))
 	return float32(f)
@@ -35,6 +40,11 @@ func Sqrt(x float32) float32 {
 	return float32(sqrt)
 }
 
+func Cbrt(x float32) float32 {
+	cbrt := math.Cbrt(float64(x))
+	return float32(cbrt)
+}
+
 func Abs(x float32) float32 {
 	if x < 0 {
 		return -x
@@ -57,6 +67,11 @@ func Pow10(n int) float32 {
 	return float32(p)
 }
 
+func Exp(x float32) float32 {
+	e := math.Exp(float64(x))
+	return float32(e)
+}
+
 func Min(x, y float32) float32 {
 	if x < y {
 		return x
@@ -256,6 +256,82 @@ func (m *Mat) Det() float64 {
 	}
 }
 
+// Solves the system of linear equations m * x = b and returns x.
+// The matrix must be square and the vector must have the same size.
+// If the matrix is singular, returns false.
+func (m *Mat) Solve(b *Vec) (*Vec, bool) {
+	if m.M != m.N {
+		err := fmt.Errorf(
+			"trying to solve a system with a non-square matrix (matrix size is (%dx%d))",
+			m.M, m.N,
+		)
+		panic(err)
+	}
+
+	if m.M != b.N {
+		err := fmt.Errorf(
+			"the matrix and vector have different dimensions (got (%dx%d) and (%d))",
+			m.M, m.N, b.N,
+		)
+		panic(err)
+	}
+
+	const EPS = 1e-12
+
+	cp := m.Copy()
+	x := b.Copy()
+
+	// Gaussian elimination with partial pivoting.
+	for i := 0; i < cp.M; i++ {
+		k := i
+
+		for j := i + 1; j < cp.M; j++ {
+			a1 := math.Abs(cp.Data[i+j*cp.N])
+			a2 := math.Abs(cp.Data[i+k*cp.N])
+			if a1 > a2 {
+				k = j
+			}
+		}
+
+		if math.Abs(cp.Data[i+k*cp.N]) < EPS {
+			return nil, false
+		}
+
+		if i != k {
+			for j := 0; j < cp.N; j++ {
+				tmp := cp.Data[j+i*cp.N]
+				cp.Data[j+i*cp.N] = cp.Data[j+k*cp.N]
+				cp.Data[j+k*cp.N] = tmp
+			}
+
+			x.Data[i], x.Data[k] = x.Data[k], x.Data[i]
+		}
+
+		for j := i + 1; j < cp.M; j++ {
+			tmp := cp.Data[i+j*cp.N] / cp.Data[i+i*cp.N]
+			if tmp == 0 {
+				continue
+			}
+
+			for l := i; l < cp.N; l++ {
+				cp.Data[l+j*cp.N] -= tmp * cp.Data[l+i*cp.N]
+			}
+			x.Data[j] -= tmp * x.Data[i]
+		}
+	}
+
+	// Back substitution.
+	for i := cp.M - 1; i >= 0; i-- {
+		sum := x.Data[i]
+		for j := i + 1; j < cp.N; j++ {
+			sum -= cp.Data[j+i*cp.N] * x.Data[j]
+		}
+		x.Data[i] = sum / cp.Data[i+i*cp.N]
+	}
+
+	return x, true
+}
+
 func (m *Mat) Trace() float64 {
 	if m.M != m.N {
 		err := fmt.Errorf(
@@ -15,3 +15,7 @@ func MaxInt(x, y int) int {
 
 	return y
 }
+
+func ClampInt(val, min, max int) int {
+	return MinInt(MaxInt(min, val), max)
+}
@@ -2,6 +2,7 @@ module github.com/infastin/gul
 
 go 1.17
 
-require github.com/srwiley/rasterx v0.0.0-20210519020934-456a8d69b780
-
-require golang.org/x/image v0.0.0-20211028202545-6944b10bf410 // indirect
+require (
+	github.com/srwiley/rasterx v0.0.0-20210519020934-456a8d69b780
+	golang.org/x/image v0.0.0-20211028202545-6944b10bf410
+)